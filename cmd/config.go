@@ -0,0 +1,123 @@
+/*
+Copyright © 2025 Tailor Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/goccy/go-yaml"
+	"github.com/spf13/cobra"
+	"github.com/tailor-platform/patterner/config"
+)
+
+var effective bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "validate and print the configuration",
+	Long:  `validate and print the configuration.`,
+	Args:  cobra.NoArgs,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "validate the config file",
+	Long:  `validate the config file and the config files it extends, reporting unknown keys with their positions.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if cfg.Path() == "" {
+			return fmt.Errorf("%s is not found", config.Filename)
+		}
+		fmt.Printf("%s is valid\n", cfg.Path())
+		return nil
+	},
+}
+
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "print the config file",
+	Long:  `print the config file, or the effective configuration merging the defaults, the extended config files, the file, the profile and the flags.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if !effective {
+			if cfg.Path() == "" {
+				return errors.New("config file not found, use --effective to print the default configuration")
+			}
+			b, err := os.ReadFile(cfg.Path())
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(b)
+			return err
+		}
+		if workspaceID != "" {
+			cfg.WorkspaceID = workspaceID
+		}
+		if otlpEndpoint != "" {
+			cfg.Telemetry.OTLPEndpoint = otlpEndpoint
+		}
+		applyFilterFlags(cfg)
+		v, err := cfg.Effective()
+		if err != nil {
+			return err
+		}
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(b)
+		return err
+	},
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "print the JSON Schema of the config file",
+	Long:  `print the JSON Schema of the config file.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		b, err := config.JSONSchema()
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configPrintCmd)
+	configCmd.AddCommand(configSchemaCmd)
+	configPrintCmd.Flags().BoolVarP(&effective, "effective", "", false, "print the effective configuration after merging the defaults, the config files, the profile and the flags")
+}
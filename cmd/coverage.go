@@ -22,18 +22,20 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/k1LoW/duration"
 	"github.com/spf13/cobra"
-	"github.com/tailor-platform/patterner/config"
 	"github.com/tailor-platform/patterner/tailor"
 )
 
 var (
-	since      string
-	fullReport bool
+	since         string
+	fullReport    bool
+	failureReport bool
 )
 
 var coverageCmd = &cobra.Command{
@@ -41,66 +43,112 @@ var coverageCmd = &cobra.Command{
 	Short: "display the pipeline resolver step coverage",
 	Long:  `display the pipeline resolver step coverage.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		spi.Start()
-		defer spi.Stop()
-		cfg, err := config.Load()
-		if err != nil {
-			return err
-		}
-		if workspaceID != "" {
-			cfg.WorkspaceID = workspaceID
-		}
-		c, err := tailor.New(cfg)
-		if err != nil {
-			return err
-		}
-		d, err := duration.Parse(since)
-		if err != nil {
-			return err
-		}
-		s := time.Now().Add(-d)
-		opts := []tailor.ResourceOption{
-			tailor.WithExecutionResults(&s),
-			tailor.WithoutApplications(),
-			tailor.WithoutStateFlow(),
-			tailor.WithoutTailorDB(),
-		}
-		resources, err := c.Resources(cmd.Context(), opts...)
-		if err != nil {
-			return err
-		}
-		spi.Disable()
-		coverage, err := c.Coverage(resources)
-		if err != nil {
-			return err
+		return runWorkspaces(cmd, runCoverage)
+	},
+}
+
+func runCoverage(ctx context.Context, r *workspaceRun) error {
+	c, err := tailor.New(r.cfg)
+	if err != nil {
+		return err
+	}
+	d, err := duration.Parse(since)
+	if err != nil {
+		return err
+	}
+	s := time.Now().Add(-d)
+	opts := []tailor.ResourceOption{
+		tailor.WithExecutionResults(&s),
+		tailor.WithoutApplications(),
+		tailor.WithoutStateFlow(),
+		tailor.WithoutTailorDB(),
+	}
+	if keepGoing {
+		opts = append(opts, tailor.WithKeepGoing())
+	}
+	if refresh {
+		opts = append(opts, tailor.WithRefresh())
+	}
+	resources, err := c.Resources(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	printFetchErrors(&r.errOut, resources)
+	coverage, err := c.Coverage(resources)
+	if err != nil {
+		return err
+	}
+	var total, covered int
+	for _, rc := range coverage {
+		if fullReport {
+			var cover float64
+			if rc.TotalSteps > 0 {
+				cover = float64(float64(rc.CoveredSteps)/float64(rc.TotalSteps)) * 100
+			}
+			fmt.Fprintf(&r.out, "%5s%% [%d/%d] %s\n", fmt.Sprintf("%.1f", cover), rc.CoveredSteps, rc.TotalSteps, rc.Name)
 		}
-		var total, covered int
+		total += rc.TotalSteps
+		covered += rc.CoveredSteps
+	}
+	if fullReport {
+		fmt.Fprintln(&r.out)
+	}
+	var coverTotal float64
+	if total > 0 {
+		coverTotal = float64(float64(covered)/float64(total)) * 100
+	}
+	fmt.Fprintf(&r.out, "%s %.1f%% [%d/%d]\n", "Pipeline resolver step coverage", coverTotal, covered, total)
+	r.summary = append(r.summary, tailor.Metric{
+		Key:   "pipeline_resolver_step_coverage_percentage",
+		Name:  "Pipeline resolver step coverage",
+		Value: coverTotal,
+		Unit:  "%",
+	})
+
+	if failureReport {
+		fmt.Fprintln(&r.out)
+		fmt.Fprintln(&r.out, "Failure report")
+		fmt.Fprintln(&r.out, "============================================================")
 		for _, rc := range coverage {
-			if fullReport {
-				var cover float64
-				if rc.TotalSteps > 0 {
-					cover = float64(float64(rc.CoveredSteps)/float64(rc.TotalSteps)) * 100
+			if rc.SucceededExecutions+rc.FailedExecutions == 0 {
+				continue
+			}
+			fmt.Fprintf(&r.out, "%s/%s: %d executions, %d failed\n", rc.PipelineNamespaceName, rc.Name, rc.SucceededExecutions+rc.FailedExecutions, rc.FailedExecutions)
+			for _, s := range rc.FailedSteps() {
+				fmt.Fprintf(&r.out, "  %s: %d failures\n", s.Name, s.FailedCount)
+				for _, e := range s.Errors {
+					fmt.Fprintf(&r.out, "    - %q (%d)\n", e.Message, e.Count)
 				}
-				fmt.Printf("%5s%% [%d/%d] %s\n", fmt.Sprintf("%.1f", cover), rc.CoveredSteps, rc.TotalSteps, rc.Name)
 			}
-			total += rc.TotalSteps
-			covered += rc.CoveredSteps
-		}
-		if fullReport {
-			fmt.Println()
-		}
-		var coverTotal float64
-		if total > 0 {
-			coverTotal = float64(float64(covered)/float64(total)) * 100
+			if steps := rc.UnsucceededSteps(); len(steps) > 0 {
+				fmt.Fprintf(&r.out, "  never succeeded: %s\n", strings.Join(steps, ", "))
+			}
 		}
-		fmt.Printf("%s %.1f%% [%d/%d]\n", "Pipeline resolver step coverage", coverTotal, covered, total)
+	}
 
+	violations := c.CheckCoverage(coverage)
+	if len(violations) == 0 {
 		return nil
-	},
+	}
+	fmt.Fprintln(&r.out)
+	for _, v := range violations {
+		if v.NotFound {
+			fmt.Fprintf(&r.out, "[%s] the coverage threshold matches no resolver; check the namespace or resolver name\n", v.Scope)
+			continue
+		}
+		fmt.Fprintf(&r.out, "[%s] coverage %.1f%% is below the minimum of %.1f%%\n", v.Scope, v.Coverage, v.Minimum)
+		for _, rc := range v.Resolvers {
+			fmt.Fprintf(&r.out, "  %s/%s [%d/%d] uncovered steps: %s\n", rc.PipelineNamespaceName, rc.Name, rc.CoveredSteps, rc.TotalSteps, strings.Join(rc.UncoveredSteps(), ", "))
+		}
+	}
+	return fmt.Errorf("%d coverage thresholds not met", len(violations))
 }
 
 func init() {
 	rootCmd.AddCommand(coverageCmd)
+	coverageCmd.Flags().BoolVarP(&refresh, "refresh", "", false, "ignore the cached resources and refetch all of them")
+	coverageCmd.Flags().BoolVarP(&keepGoing, "keep-going", "", false, "continue with the resources that could be fetched when some namespaces or resources cannot be fetched")
 	coverageCmd.Flags().StringVarP(&since, "since", "s", "30min", "only consider executions since the given duration (e.g., 24hours, 30min, 15sec)")
 	coverageCmd.Flags().BoolVarP(&fullReport, "full-report", "f", false, "display full report")
+	coverageCmd.Flags().BoolVarP(&failureReport, "failure-report", "", false, "display failed executions and steps never executed on the success path")
 }
@@ -0,0 +1,178 @@
+/*
+Copyright © 2025 Tailor Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tailor-platform/patterner/tailor"
+)
+
+var fixDiff bool
+
+var fixCmd = &cobra.Command{
+	Use:   "fix [PATH...]",
+	Short: "fix the deprecated features in the local manifests",
+	Long: `fix the deprecated features with mechanical fixes in the local CUE manifests.
+
+It rewrites the CEL scripts (PreScript, PostScript) into hooks and the CEL hooks of the TailorDB fields (CreateExpr, UpdateExpr) into script hooks in place,
+or prints the changes as a unified diff with --diff, and reports the deprecated features that could not be fixed.
+
+The CEL validations (PreValidation, PostValidation) become hooks that throw an error when the condition is false and otherwise
+return the input (context.args) or the output (args) of the step unchanged. They are reported with a note to review the error message
+and the returned value. A validation is not fixed when its step also has the script becoming the same hook: merge them by hand.
+
+Unlike lint, which inspects the resources deployed in the workspace, fix matches the deprecated fields line by line in the CUE files:
+only a field with a single-line string on its own line is rewritten, and the fields generated by CUE expressions are not found.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+		paths, err := manifestPaths(args)
+		if err != nil {
+			return err
+		}
+		var fixed, unfixed int
+		for _, path := range paths {
+			src, err := os.ReadFile(path) //nolint:gosec
+			if err != nil {
+				return err
+			}
+			dst, fixes := tailor.FixManifest(cfg, src)
+			report := os.Stdout
+			if fixDiff {
+				report = os.Stderr
+			}
+			for _, f := range fixes {
+				if f.Fixed() {
+					fixed++
+					fmt.Fprintf(report, "%s:%d: [%s] %s -> %s\n", path, f.Line, f.Rule, f.Field, f.Replacement)
+					if f.Note != "" {
+						fmt.Fprintf(report, "  review: %s\n", f.Note)
+					}
+					continue
+				}
+				unfixed++
+				fmt.Fprintf(report, "%s:%d: [%s] %s not fixed: %s\n", path, f.Line, f.Rule, f.Field, f.Reason)
+			}
+			if bytes.Equal(src, dst) {
+				continue
+			}
+			if fixDiff {
+				writeUnifiedDiff(os.Stdout, path, string(src), string(dst))
+				continue
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, dst, info.Mode().Perm()); err != nil {
+				return err
+			}
+		}
+		if unfixed > 0 {
+			return fmt.Errorf("%d deprecated features fixed, %d could not be fixed", fixed, unfixed)
+		}
+		return nil
+	},
+}
+
+// manifestPaths returns the CUE files of the paths, walking the directories.
+func manifestPaths(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		err := filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != arg && (d.Name() == "cue.mod" || strings.HasPrefix(d.Name(), ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if path == arg || filepath.Ext(path) == ".cue" {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// writeUnifiedDiff writes the unified diff of the contents, whose lines are replaced one by one without insertions or deletions.
+func writeUnifiedDiff(w io.Writer, path, src, dst string) {
+	const context = 3
+	// The newline ending the last line does not start another line.
+	a, b := strings.Split(strings.TrimSuffix(src, "\n"), "\n"), strings.Split(strings.TrimSuffix(dst, "\n"), "\n")
+	writeLine := func(prefix string, lines []string, content string, i int) {
+		fmt.Fprintf(w, "%s%s\n", prefix, lines[i])
+		if i == len(lines)-1 && !strings.HasSuffix(content, "\n") {
+			fmt.Fprintln(w, "\\ No newline at end of file")
+		}
+	}
+	var changed []int
+	for i := range a {
+		if a[i] != b[i] {
+			changed = append(changed, i)
+		}
+	}
+	fmt.Fprintf(w, "--- a/%s\n+++ b/%s\n", filepath.ToSlash(path), filepath.ToSlash(path))
+	for len(changed) > 0 {
+		start := max(changed[0]-context, 0)
+		end := min(changed[0]+context+1, len(a))
+		n := 1
+		for n < len(changed) && changed[n]-context <= end {
+			end = min(changed[n]+context+1, len(a))
+			n++
+		}
+		fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", start+1, end-start, start+1, end-start)
+		for i := start; i < end; i++ {
+			if a[i] == b[i] {
+				writeLine(" ", a, src, i)
+				continue
+			}
+			writeLine("-", a, src, i)
+			writeLine("+", b, dst, i)
+		}
+		changed = changed[n:]
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(fixCmd)
+	fixCmd.Flags().BoolVarP(&fixDiff, "diff", "", false, "print the changes as a unified diff instead of rewriting the files")
+}
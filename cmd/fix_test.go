@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteUnifiedDiff(t *testing.T) {
+	git, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git is required to apply the diff")
+	}
+	tests := []struct {
+		name string
+		src  string
+		dst  string
+	}{
+		{
+			name: "change near the end",
+			src:  "{\n\tName: \"a\"\n\tPreScript: \"context.args\"\n}\n",
+			dst:  "{\n\tName: \"a\"\n\tPreHook: {Expr: \"context.args\"}\n}\n",
+		},
+		{
+			name: "no newline at end of file",
+			src:  "{\n\tName: \"a\"\n\tPreScript: \"context.args\"\n}",
+			dst:  "{\n\tName: \"a\"\n\tPreHook: {Expr: \"context.args\"}\n}",
+		},
+		{
+			name: "change on the last line without newline",
+			src:  "a\nb\nc\nd\ne\nf\ng\nh\nPreScript: \"x\"",
+			dst:  "a\nb\nc\nd\ne\nf\ng\nh\nPreHook: {Expr: \"x\"}",
+		},
+		{
+			name: "separate hunks",
+			src:  "PreScript: \"x\"\n1\n2\n3\n4\n5\n6\n7\n8\nPostScript: \"y\"\n",
+			dst:  "PreHook: {Expr: \"x\"}\n1\n2\n3\n4\n5\n6\n7\n8\nPostHook: {Expr: \"y\"}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "manifest.cue")
+			if err := os.WriteFile(path, []byte(tt.src), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			var diff bytes.Buffer
+			writeUnifiedDiff(&diff, "manifest.cue", tt.src, tt.dst)
+			cmd := exec.Command(git, "apply", "-") //nolint:gosec
+			cmd.Dir = dir
+			cmd.Stdin = &diff
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git apply failed: %v\n%s\ndiff:\n%s", err, out, strings.ReplaceAll(diff.String(), "\t", "  "))
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.dst {
+				t.Errorf("applied diff = %q, want %q", got, tt.dst)
+			}
+		})
+	}
+}
@@ -22,34 +22,61 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/goccy/go-yaml"
+	"github.com/k1LoW/duration"
 	"github.com/spf13/cobra"
 	"github.com/tailor-platform/patterner/config"
+	"github.com/tailor-platform/patterner/tailor"
+)
+
+var (
+	fromWorkspace bool
+	// baselineSince is separate from since, as the baseline needs a longer window of the executions for the coverage.
+	baselineSince string
 )
 
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "initialize config file",
-	Long:  `initialize config file.`,
+	Long: `initialize config file.
+
+With --from-workspace, it lints the workspace once and generates a config file that accepts the current state of the workspace as the baseline:
+the existing lint warnings are ignored, stepCount.max is raised to the current maximum and the octocov acceptables reflect the current metric values.
+It fails when some resources cannot be fetched, so that a transient failure is not baselined.
+
+The environment variables, --set and --token-file apply to the generated config file and to the fetch of the workspace.`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.New()
+		if _, err := os.Stat(config.Filename); err == nil {
+			return errors.New("config file already exists")
+		}
+		cfg, err := initConfig()
 		if err != nil {
 			return err
 		}
-		if workspaceID != "" {
-			cfg.WorkspaceID = workspaceID
+		if fromWorkspace {
+			if cfg.WorkspaceID == "" {
+				return errors.New("--workspace-id is required with --from-workspace")
+			}
+			spi.Start()
+			err := baseline(cmd.Context(), cfg)
+			spi.Stop()
+			if err != nil {
+				return err
+			}
 		}
 		b, err := yaml.Marshal(cfg)
 		if err != nil {
 			return err
 		}
-		if _, err := os.Stat(config.Filename); err == nil {
-			return errors.New("config file already exists")
-		}
-
 		if err := os.WriteFile(config.Filename, b, 0600); err != nil {
 			return err
 		}
@@ -57,6 +84,104 @@ var initCmd = &cobra.Command{
 	},
 }
 
+// initConfig returns the configuration to generate: the defaults with the overrides given by the environment variables
+// and the flags, as the file to read does not exist yet.
+func initConfig() (*config.Config, error) {
+	cfg, err := config.Load(append(loadOptions(), config.WithoutFile())...)
+	if err != nil {
+		return nil, err
+	}
+	if workspaceID != "" {
+		cfg.WorkspaceID = workspaceID
+	}
+	return cfg, nil
+}
+
+// baseline tailors the configuration to the current state of the workspace.
+func baseline(ctx context.Context, cfg *config.Config) error {
+	c, err := tailor.New(cfg)
+	if err != nil {
+		return err
+	}
+	d, err := duration.Parse(baselineSince)
+	if err != nil {
+		return err
+	}
+	s := time.Now().Add(-d)
+	resources, err := c.Resources(ctx, tailor.WithExecutionResults(&s))
+	if err != nil {
+		return err
+	}
+	if len(resources.FetchErrors) > 0 {
+		spi.Disable()
+		printFetchErrors(os.Stderr, resources)
+		return errors.New("some resources could not be fetched; run init again so that they are not baselined")
+	}
+	executions := 0
+	for _, p := range resources.Pipelines {
+		for _, r := range p.Resolvers {
+			executions += len(r.ExecutionResults)
+		}
+	}
+	if executions == 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: no execution results since %s, so the coverage acceptables are baselined at no coverage; use a longer --since\n", baselineSince)
+	}
+
+	var maxSteps int
+	for _, p := range resources.Pipelines {
+		for _, r := range p.Resolvers {
+			maxSteps = max(maxSteps, len(r.Steps))
+		}
+	}
+	if maxSteps > cfg.Lint.Rules.Pipeline.StepCount.Max {
+		cfg.Lint.Rules.Pipeline.StepCount.Max = maxSteps
+	}
+
+	warns, err := c.Lint(resources)
+	if err != nil {
+		return err
+	}
+	for _, w := range warns {
+		if w.Rule == tailor.LintRulePipelineFetchError || w.Rule == tailor.LintRuleTailorDBFetchError {
+			// A fetch error is not a state of the workspace to accept.
+			continue
+		}
+		ig := config.LintIgnore{Rule: string(w.Rule), Resource: w.Namespace}
+		if w.Resource != "" {
+			ig.Resource = fmt.Sprintf("%s/%s", w.Namespace, w.Resource)
+		}
+		if !slices.Contains(cfg.Lint.Ignore, ig) {
+			cfg.Lint.Ignore = append(cfg.Lint.Ignore, ig)
+		}
+	}
+
+	metrics, err := c.Metrics(resources)
+	if err != nil {
+		return err
+	}
+	for _, m := range metrics {
+		if m.Error != nil {
+			continue
+		}
+		switch m.Key {
+		case "pipeline_resolver_step_coverage_percentage":
+			cfg.Metrics.Octocov.Acceptables = append(cfg.Metrics.Octocov.Acceptables, fmt.Sprintf("current.%s >= %.1f", m.Key, math.Floor(m.Value*10)/10))
+		case "lint_warnings_total", "pipeline_resolver_complexity_max":
+			cfg.Metrics.Octocov.Acceptables = append(cfg.Metrics.Octocov.Acceptables, fmt.Sprintf("current.%s <= %.0f", m.Key, m.Value))
+		}
+	}
+
+	spi.Disable()
+	fmt.Printf("stepCount.max: %d\n", cfg.Lint.Rules.Pipeline.StepCount.Max)
+	fmt.Printf("lint.ignore: %d entries for %d existing warnings\n", len(cfg.Lint.Ignore), len(warns))
+	for _, a := range cfg.Metrics.Octocov.Acceptables {
+		fmt.Printf("metrics.octocov.acceptables: %s\n", a)
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().BoolVarP(&fromWorkspace, "from-workspace", "", false, "lint the workspace and generate a config file accepting its current state as the baseline")
+	initCmd.Flags().StringVarP(&baselineSince, "since", "s", "7days", "only consider executions since the given duration for the baseline metrics (e.g., 7days, 24hours, 30min)")
 }
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tailor-platform/patterner/config"
+)
+
+func TestInitConfig(t *testing.T) {
+	dir := t.TempDir()
+	// The configuration file of a parent directory is not the file to generate.
+	if err := os.WriteFile(filepath.Join(dir, config.Filename), []byte("workspaceID: parent\napi:\n  concurrency: 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	wd := filepath.Join(dir, "repo")
+	if err := os.Mkdir(wd, 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(wd)
+	t.Setenv(config.EnvName("lint.rules.pipeline.stepCount.max"), "42")
+	path := filepath.Join(dir, "token")
+	workspaceID, tokenFile, sets = "ws", path, []string{"api.maxRetries=7"}
+	t.Cleanup(func() { workspaceID, tokenFile, sets = "", "", nil })
+
+	cfg, err := initConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.WorkspaceID != "ws" {
+		t.Errorf("workspaceID: got %s, want ws", cfg.WorkspaceID)
+	}
+	if cfg.Auth.TokenFile != path {
+		t.Errorf("auth.tokenFile: got %s, want %s", cfg.Auth.TokenFile, path)
+	}
+	if cfg.API.MaxRetries != 7 {
+		t.Errorf("api.maxRetries: got %d, want 7", cfg.API.MaxRetries)
+	}
+	if cfg.Lint.Rules.Pipeline.StepCount.Max != 42 {
+		t.Errorf("lint.rules.pipeline.stepCount.max: got %d, want 42", cfg.Lint.Rules.Pipeline.StepCount.Max)
+	}
+	def, err := config.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.API.Concurrency != def.API.Concurrency {
+		t.Errorf("api.concurrency: got %d, want the default %d", cfg.API.Concurrency, def.API.Concurrency)
+	}
+	if cfg.Path() != "" {
+		t.Errorf("no configuration file should be loaded, got %s", cfg.Path())
+	}
+}
@@ -22,10 +22,10 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
-	"github.com/tailor-platform/patterner/config"
 	"github.com/tailor-platform/patterner/tailor"
 )
 
@@ -35,41 +35,49 @@ var lintCmd = &cobra.Command{
 	Long:  `lint the resources in the specified workspace.`,
 	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		spi.Start()
-		defer spi.Stop()
-		cfg, err := config.Load()
-		if err != nil {
-			return err
-		}
-		if workspaceID != "" {
-			cfg.WorkspaceID = workspaceID
-		}
-		c, err := tailor.New(cfg)
-		if err != nil {
-			return err
-		}
-		resources, err := c.Resources(cmd.Context())
-		if err != nil {
-			return err
-		}
-		spi.Disable()
-		warns, err := c.Lint(resources)
-		if err != nil {
-			return err
-		}
-		for _, w := range warns {
-			fmt.Printf("[%s] %s: %s\n", w.Type, w.Name, w.Message)
-		}
-		if len(warns) > cfg.Lint.Acceptable {
-			if cfg.Lint.Acceptable == 0 {
-				return fmt.Errorf("%d warnings found", len(warns))
-			}
-			return fmt.Errorf("%d warnings found, which exceeds the acceptable number of %d", len(warns), cfg.Lint.Acceptable)
-		}
-		return nil
+		return runWorkspaces(cmd, runLint)
 	},
 }
 
+func runLint(ctx context.Context, r *workspaceRun) error {
+	c, err := tailor.New(r.cfg)
+	if err != nil {
+		return err
+	}
+	var opts []tailor.ResourceOption
+	if keepGoing {
+		opts = append(opts, tailor.WithKeepGoing())
+	}
+	if refresh {
+		opts = append(opts, tailor.WithRefresh())
+	}
+	resources, err := c.Resources(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	warns, err := c.Lint(resources)
+	if err != nil {
+		return err
+	}
+	for _, w := range warns {
+		fmt.Fprintf(&r.out, "[%s] %s: %s\n", w.Type, w.Name, w.Message)
+	}
+	r.summary = append(r.summary, tailor.Metric{
+		Key:   "lint_warnings_total",
+		Name:  "Total number of lint warnings",
+		Value: float64(len(warns)),
+	})
+	if len(warns) > r.cfg.Lint.Acceptable {
+		if r.cfg.Lint.Acceptable == 0 {
+			return fmt.Errorf("%d warnings found", len(warns))
+		}
+		return fmt.Errorf("%d warnings found, which exceeds the acceptable number of %d", len(warns), r.cfg.Lint.Acceptable)
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(lintCmd)
+	lintCmd.Flags().BoolVarP(&refresh, "refresh", "", false, "ignore the cached resources and refetch all of them")
+	lintCmd.Flags().BoolVarP(&keepGoing, "keep-going", "", false, "continue with the resources that could be fetched when some namespaces or resources cannot be fetched")
 }
@@ -0,0 +1,68 @@
+/*
+Copyright © 2025 Tailor Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tailor-platform/patterner/tailor"
+)
+
+var clientID string
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "log in to Tailor Platform with the device authorization flow",
+	Long: `log in to Tailor Platform with the OAuth 2.0 device authorization flow.
+
+It shows a code to enter at the verification URL in a browser, waits for the login to be approved
+and saves the credential in the credentials file (auth.credentialsFile).
+The other commands use the credential when no other credential is given, refreshing its token when it expires.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if clientID != "" {
+			cfg.Auth.ClientID = clientID
+		}
+		path, err := tailor.Login(cmd.Context(), cfg.Auth, func(a *tailor.DeviceAuthorization) {
+			if a.VerificationURIComplete != "" {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Open %s and confirm the code %s to log in.\n", a.VerificationURIComplete, a.UserCode)
+				return
+			}
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Open %s and enter the code %s to log in.\n", a.VerificationURI, a.UserCode)
+		})
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Logged in. The credential is saved in %s.\n", path)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(loginCmd)
+	loginCmd.Flags().StringVarP(&clientID, "client-id", "", "", "OAuth 2.0 client ID to log in with (overrides auth.clientID)")
+}
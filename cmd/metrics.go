@@ -22,25 +22,33 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/k1LoW/duration"
-	"github.com/olekukonko/tablewriter"
-	"github.com/olekukonko/tablewriter/renderer"
 	"github.com/olekukonko/tablewriter/tw"
 	"github.com/spf13/cobra"
 	"github.com/tailor-platform/patterner/config"
+	"github.com/tailor-platform/patterner/history"
 	"github.com/tailor-platform/patterner/tailor"
+	"github.com/tailor-platform/patterner/telemetry"
+	"github.com/tailor-platform/patterner/version"
 )
 
 var (
 	outOctocovPath         string
 	withLintWarnings       bool
 	withCoverageFullReport bool
+	withComplexityReport   bool
+	complexityReportTop    int
+	record                 bool
+	groupBy                string
 )
 
 var metricsCmd = &cobra.Command{
@@ -49,167 +57,304 @@ var metricsCmd = &cobra.Command{
 	Long:  `retrieve and display metrics about the resources in the specified workspace.`,
 	Args:  cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		spi.Start()
-		defer spi.Stop()
-		cfg, err := config.Load()
+		return runWorkspaces(cmd, runMetrics)
+	},
+}
+
+func runMetrics(ctx context.Context, r *workspaceRun) error {
+	c, err := tailor.New(r.cfg)
+	if err != nil {
+		return err
+	}
+	d, err := duration.Parse(since)
+	if err != nil {
+		return err
+	}
+	s := time.Now().Add(-d)
+	opts := []tailor.ResourceOption{
+		tailor.WithExecutionResults(&s),
+	}
+	if keepGoing {
+		opts = append(opts, tailor.WithKeepGoing())
+	}
+	if refresh {
+		opts = append(opts, tailor.WithRefresh())
+	}
+	resources, err := c.Resources(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	fetchedAt := time.Now()
+	printFetchErrors(&r.errOut, resources)
+
+	if withLintWarnings {
+		fmt.Fprintln(&r.out, "Lint warnings")
+		fmt.Fprintln(&r.out, "============================================================")
+		warns, err := c.Lint(resources)
 		if err != nil {
 			return err
 		}
-		if workspaceID != "" {
-			cfg.WorkspaceID = workspaceID
+		for _, w := range warns {
+			fmt.Fprintf(&r.out, "[%s] %s: %s\n", w.Type, w.Name, w.Message)
 		}
-		c, err := tailor.New(cfg)
+		fmt.Fprintln(&r.out)
+	}
+
+	if withCoverageFullReport {
+		fmt.Fprintln(&r.out, "Coverage")
+		fmt.Fprintln(&r.out, "============================================================")
+		coverages, err := c.Coverage(resources)
 		if err != nil {
 			return err
 		}
-		d, err := duration.Parse(since)
+		for _, rc := range coverages {
+			var cover float64
+			if rc.TotalSteps > 0 {
+				cover = float64(float64(rc.CoveredSteps)/float64(rc.TotalSteps)) * 100
+			}
+			fmt.Fprintf(&r.out, "%5s%% [%d/%d] %s\n", fmt.Sprintf("%.1f", cover), rc.CoveredSteps, rc.TotalSteps, rc.Name)
+		}
+		fmt.Fprintln(&r.out)
+	}
+
+	if withComplexityReport {
+		fmt.Fprintln(&r.out, "Complexity")
+		fmt.Fprintln(&r.out, "============================================================")
+		complexities, err := c.Complexity(resources)
 		if err != nil {
 			return err
 		}
-		s := time.Now().Add(-d)
-		resources, err := c.Resources(cmd.Context(), tailor.WithExecutionResults(&s))
-		if err != nil {
+		table := newTable(&r.out, tw.AlignLeft, tw.AlignRight, tw.AlignRight, tw.AlignRight, tw.AlignRight, tw.AlignRight, tw.AlignRight)
+		table.Header("Resolver", "Score", "Steps", "Tests", "Scripts", "Depth", "Types")
+		most := tailor.MostComplex(complexities, complexityReportTop)
+		data := make([][]string, 0, len(most))
+		for _, rc := range most {
+			data = append(data, []string{
+				fmt.Sprintf("%s/%s", rc.PipelineNamespaceName, rc.Name),
+				fmt.Sprintf("%d", rc.Score()),
+				fmt.Sprintf("%d", rc.Steps),
+				fmt.Sprintf("%d", rc.TestBranches),
+				fmt.Sprintf("%d", rc.Scripts),
+				fmt.Sprintf("%d", rc.SelectionDepth),
+				fmt.Sprintf("%d", rc.Types),
+			})
+		}
+		if err := table.Bulk(data); err != nil {
 			return err
 		}
-		spi.Disable()
+		if err := table.Render(); err != nil {
+			return err
+		}
+		fmt.Fprintln(&r.out)
+	}
+
+	if withCoverageFullReport || withLintWarnings || withComplexityReport {
+		fmt.Fprintln(&r.out, "Metrics")
+		fmt.Fprintln(&r.out, "============================================================")
+	}
 
-		if withLintWarnings {
-			fmt.Println("Lint warnings")
-			fmt.Println("============================================================")
-			warns, err := c.Lint(resources)
+	metrics, err := c.Metrics(resources)
+	if err != nil {
+		return err
+	}
+	r.summary = metrics
+	table := newTable(&r.out, tw.AlignLeft, tw.AlignRight)
+	data := make([][]string, 0, len(metrics))
+	for _, m := range metrics {
+		if m.Error != nil {
+			data = append(data, []string{m.Name, fmt.Sprintf("Error: %v", m.Error)})
+			continue
+		}
+		data = append(data, []string{m.Name, formatMetricValue(m.Value, m.Unit)})
+	}
+	if err := table.Bulk(data); err != nil {
+		return err
+	}
+	if err := table.Render(); err != nil {
+		return err
+	}
+	if groupBy != "" {
+		var dimensions []tailor.MetricDimension
+		switch groupBy {
+		case "namespace":
+			dimensions = []tailor.MetricDimension{tailor.MetricDimensionPipelineNamespace, tailor.MetricDimensionTailorDBNamespace}
+		case "resolver":
+			dimensions = []tailor.MetricDimension{tailor.MetricDimensionResolver}
+		default:
+			return fmt.Errorf("invalid --group-by value: %s (must be namespace or resolver)", groupBy)
+		}
+		for _, dimension := range dimensions {
+			groups, err := c.MetricsBy(resources, dimension)
 			if err != nil {
 				return err
 			}
-			for _, w := range warns {
-				fmt.Printf("[%s] %s: %s\n", w.Type, w.Name, w.Message)
+			if len(groups) == 0 {
+				continue
 			}
-			fmt.Println()
-		}
-
-		if withCoverageFullReport {
-			fmt.Println("Coverage")
-			fmt.Println("============================================================")
-			coverages, err := c.Coverage(resources)
-			if err != nil {
+			fmt.Fprintln(&r.out)
+			fmt.Fprintf(&r.out, "Metrics by %s\n", strings.ReplaceAll(string(dimension), "_", " "))
+			fmt.Fprintln(&r.out, "============================================================")
+			table := newTable(&r.out, tw.AlignLeft, tw.AlignLeft, tw.AlignRight)
+			var data [][]string
+			for _, g := range groups {
+				for i, m := range g.Metrics {
+					name := ""
+					if i == 0 {
+						name = g.Name()
+					}
+					if m.Error != nil {
+						data = append(data, []string{name, m.Name, fmt.Sprintf("Error: %v", m.Error)})
+						continue
+					}
+					data = append(data, []string{name, m.Name, formatMetricValue(m.Value, m.Unit)})
+				}
+			}
+			if err := table.Bulk(data); err != nil {
 				return err
 			}
-			for _, rc := range coverages {
-				var cover float64
-				if rc.TotalSteps > 0 {
-					cover = float64(float64(rc.CoveredSteps)/float64(rc.TotalSteps)) * 100
-				}
-				fmt.Printf("%5s%% [%d/%d] %s\n", fmt.Sprintf("%.1f", cover), rc.CoveredSteps, rc.TotalSteps, rc.Name)
+			if err := table.Render(); err != nil {
+				return err
 			}
-			fmt.Println()
 		}
-
-		if withCoverageFullReport || withLintWarnings {
-			fmt.Println("Metrics")
-			fmt.Println("============================================================")
-		}
-
-		metrics, err := c.Metrics(resources)
-		if err != nil {
+	}
+	if r.endpoint != nil {
+		if err := telemetry.PushMetrics(ctx, r.endpoint, r.cfg.WorkspaceID, metrics); err != nil {
 			return err
 		}
-		table := tablewriter.NewTable(os.Stdout,
-			tablewriter.WithTrimSpace(tw.Off),
-			tablewriter.WithRenderer(renderer.NewBlueprint(tw.Rendition{
-				Borders: tw.BorderNone,
-				Symbols: tw.NewSymbols(tw.StyleNone),
-				Settings: tw.Settings{
-					Lines: tw.Lines{
-						ShowTop:        tw.Off,
-						ShowBottom:     tw.Off,
-						ShowHeaderLine: tw.Off,
-						ShowFooterLine: tw.Off,
-					},
-					Separators: tw.Separators{
-						ShowHeader:     tw.Off,
-						ShowFooter:     tw.Off,
-						BetweenRows:    tw.Off,
-						BetweenColumns: tw.Off,
-					},
-				},
-			})),
-			tablewriter.WithHeaderConfig(tw.CellConfig{
-				Formatting: tw.CellFormatting{
-					AutoFormat: tw.Off,
-					Alignment:  tw.AlignLeft,
-				},
-				Padding: tw.CellPadding{
-					Global: tw.Padding{Left: tw.Space, Right: tw.Space, Top: tw.Empty, Bottom: tw.Empty},
-				},
-			}),
-			tablewriter.WithRowConfig(tw.CellConfig{
-				Formatting: tw.CellFormatting{
-					AutoFormat: tw.Off,
-				},
-				ColumnAligns: []tw.Align{tw.AlignLeft, tw.AlignRight},
-				Padding: tw.CellPadding{
-					Global: tw.Padding{Left: tw.Space, Right: tw.Space, Top: tw.Empty, Bottom: tw.Empty},
-				},
-			}),
-		)
-		data := make([][]string, 0, len(metrics))
+	}
+	if record {
+		rec := &history.Record{
+			Timestamp:   time.Now(),
+			WorkspaceID: r.cfg.WorkspaceID,
+			Version:     version.Version,
+		}
 		for _, m := range metrics {
 			if m.Error != nil {
-				data = append(data, []string{m.Name, fmt.Sprintf("Error: %v", m.Error)})
 				continue
 			}
-			if m.Value == (math.Round(m.Value*10) / 10) {
-				data = append(data, []string{m.Name, fmt.Sprintf("%.0f%s", m.Value, m.Unit)})
-			} else {
-				data = append(data, []string{m.Name, fmt.Sprintf("%.1f%s", m.Value, m.Unit)})
-			}
+			rec.Metrics = append(rec.Metrics, &history.Metric{
+				Key:   m.Key,
+				Name:  m.Name,
+				Value: m.Value,
+				Unit:  m.Unit,
+			})
 		}
-		if err := table.Bulk(data); err != nil {
+		if err := history.Append(r.cfg.HistoryPath(), rec); err != nil {
 			return err
 		}
-		if err := table.Render(); err != nil {
-			return err
+	}
+	if outOctocovPath != "" {
+		metadata := []*MetadataKV{
+			{
+				Key:   "workspace_id",
+				Value: r.cfg.WorkspaceID,
+			},
+			{
+				Key:   "since",
+				Name:  "Execution results since",
+				Value: since,
+			},
+			{
+				Key:   "patterner_version",
+				Name:  "Patterner version",
+				Value: version.Version,
+			},
+			{
+				Key:   "fetched_at",
+				Name:  "Fetched at",
+				Value: fetchedAt.UTC().Format(time.RFC3339),
+			},
 		}
-		if outOctocovPath != "" {
-			metricSet := &CustomMetricSet{
-				Key:  "workspace_metrics",
-				Name: "Workspace metrics using [Patterner](https://github.com/tailor-platform/patterner)",
-				Metadata: []*MetadataKV{
-					{
-						Key:   "workspace_id",
-						Value: cfg.WorkspaceID,
-					},
-				},
-			}
-			for _, m := range metrics {
-				metricSet.Metrics = append(metricSet.Metrics, &CustomMetric{
-					Key:   m.Key,
-					Name:  m.Name,
-					Value: m.Value,
-					Unit:  m.Unit,
-				})
-			}
-			metricSet.Acceptables = append(metricSet.Acceptables, cfg.Metrics.Octocov.Acceptables...)
+		metricSet := &CustomMetricSet{
+			Key:      "workspace_metrics",
+			Name:     "Workspace metrics using [Patterner](https://github.com/tailor-platform/patterner)",
+			Metadata: metadata,
+		}
+		for _, m := range metrics {
+			metricSet.Metrics = append(metricSet.Metrics, &CustomMetric{
+				Key:   m.Key,
+				Name:  m.Name,
+				Value: m.Value,
+				Unit:  m.Unit,
+			})
+		}
+		metricSet.Acceptables = append(metricSet.Acceptables, r.cfg.Metrics.Octocov.Acceptables...)
 
-			csets := []*CustomMetricSet{metricSet}
-			b, err := json.MarshalIndent(csets, "", "  ")
+		csets := []*CustomMetricSet{metricSet}
+		for _, dimension := range []tailor.MetricDimension{tailor.MetricDimensionPipelineNamespace, tailor.MetricDimensionTailorDBNamespace} {
+			groups, err := c.MetricsBy(resources, dimension)
 			if err != nil {
 				return err
 			}
-			if err := os.WriteFile(outOctocovPath, b, 0644); err != nil { //nolint:gosec
-				return err
+			for _, g := range groups {
+				csets = append(csets, namespaceMetricSet(r.cfg, g, metadata))
 			}
 		}
+		b, err := json.MarshalIndent(csets, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(r.outputPath(outOctocovPath), b, 0644); err != nil { //nolint:gosec
+			return err
+		}
+	}
 
-		return nil
-	},
+	return nil
 }
 
 func init() {
 	rootCmd.AddCommand(metricsCmd)
 	metricsCmd.Flags().StringVarP(&since, "since", "s", "30min", "only consider executions since the given duration (e.g., 24hours, 30min, 15sec)")
+	metricsCmd.Flags().BoolVarP(&refresh, "refresh", "", false, "ignore the cached resources and refetch all of them")
+	metricsCmd.Flags().BoolVarP(&keepGoing, "keep-going", "", false, "continue with the resources that could be fetched when some namespaces or resources cannot be fetched")
 	metricsCmd.Flags().StringVarP(&outOctocovPath, "out-octocov-path", "", "", "output the metrics in octocov custom metrics format to the specified file (e.g., ./metrics.json)")
 	metricsCmd.Flags().BoolVarP(&withLintWarnings, "with-lint-warnings", "", false, "display the lint warnings along with the metrics")
 	metricsCmd.Flags().BoolVarP(&withCoverageFullReport, "with-coverage-full-report", "", false, "display the coverage full report along with the metrics")
+	metricsCmd.Flags().BoolVarP(&withComplexityReport, "with-complexity-report", "", false, "display the most complex resolvers along with the metrics")
+	metricsCmd.Flags().IntVarP(&complexityReportTop, "complexity-report-top", "", 10, "number of resolvers to display in the complexity report")
+	metricsCmd.Flags().StringVarP(&groupBy, "group-by", "", "", "also display the metrics broken down by namespace or resolver")
+	metricsCmd.Flags().BoolVarP(&record, "record", "", false, "append the metrics to the history file (metrics.history.path)")
+}
+
+// namespaceMetricSet converts the metrics of a namespace into an octocov custom metric set.
+func namespaceMetricSet(cfg *config.Config, g *tailor.MetricGroup, metadata []*MetadataKV) *CustomMetricSet {
+	var kind, label string
+	acceptables := cfg.Metrics.Octocov.Pipelines
+	switch g.Dimension {
+	case tailor.MetricDimensionTailorDBNamespace:
+		kind, label = "tailordb", "TailorDB"
+		acceptables = cfg.Metrics.Octocov.TailorDBs
+	default:
+		kind, label = "pipeline", "Pipeline"
+	}
+	set := &CustomMetricSet{
+		Key:  fmt.Sprintf("%s_namespace_metrics_%s", kind, g.Namespace),
+		Name: fmt.Sprintf("%s namespace `%s` metrics using [Patterner](https://github.com/tailor-platform/patterner)", label, g.Namespace),
+		Metadata: append(slices.Clone(metadata), &MetadataKV{
+			Key:   "namespace",
+			Name:  fmt.Sprintf("%s namespace", label),
+			Value: g.Namespace,
+		}),
+	}
+	for _, m := range g.Metrics {
+		set.Metrics = append(set.Metrics, &CustomMetric{
+			Key:   m.Key,
+			Name:  m.Name,
+			Value: m.Value,
+			Unit:  m.Unit,
+		})
+	}
+	if ns, ok := acceptables[g.Namespace]; ok {
+		set.Acceptables = append(set.Acceptables, ns.Acceptables...)
+	}
+	return set
+}
+
+func formatMetricValue(v float64, unit string) string {
+	if v == (math.Round(v*10) / 10) {
+		return fmt.Sprintf("%.0f%s", v, unit)
+	}
+	return fmt.Sprintf("%.1f%s", v, unit)
 }
 
 // copy from github.com/k1LoW/octocov/report
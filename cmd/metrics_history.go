@@ -0,0 +1,132 @@
+/*
+Copyright © 2025 Tailor Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter/tw"
+	"github.com/spf13/cobra"
+	"github.com/tailor-platform/patterner/history"
+)
+
+var (
+	historyLimit int
+	historyKey   string
+)
+
+var metricsHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "display the recorded metrics over time",
+	Long:  `display the metrics recorded with 'patterner metrics --record' over time.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if workspaceID != "" {
+			cfg.WorkspaceID = workspaceID
+		}
+		if cfg.WorkspaceID == "" {
+			if len(cfg.Workspaces) > 0 {
+				names := make([]string, 0, len(cfg.Workspaces))
+				for _, w := range cfg.Workspaces {
+					names = append(names, fmt.Sprintf("%s (%s)", w.Name, w.WorkspaceID))
+				}
+				return fmt.Errorf("--workspace-id is required to select one of the workspaces: %s", strings.Join(names, ", "))
+			}
+			return errors.New("--workspace-id is required")
+		}
+		path := cfg.HistoryPath()
+		records, err := history.Load(path, cfg.WorkspaceID)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return fmt.Errorf("no metrics of workspace %s recorded in %s", cfg.WorkspaceID, path)
+		}
+		if historyLimit > 0 && len(records) > historyLimit {
+			records = records[len(records)-historyLimit:]
+		}
+		series := history.SeriesOf(records)
+
+		if historyKey != "" {
+			for _, s := range series {
+				if s.Key != historyKey {
+					continue
+				}
+				fmt.Println(s.Name)
+				fmt.Println("============================================================")
+				table := newTable(os.Stdout, tw.AlignLeft, tw.AlignRight, tw.AlignRight)
+				table.Header("Recorded at", "Value", "Delta")
+				data := make([][]string, 0, len(s.Points))
+				for i, p := range s.Points {
+					delta := ""
+					if i > 0 {
+						delta = formatMetricDelta(p.Value-s.Points[i-1].Value, s.Unit)
+					}
+					data = append(data, []string{p.Timestamp.Local().Format("2006-01-02 15:04:05"), formatMetricValue(p.Value, s.Unit), delta})
+				}
+				if err := table.Bulk(data); err != nil {
+					return err
+				}
+				return table.Render()
+			}
+			return fmt.Errorf("metric %q is not recorded", historyKey)
+		}
+
+		fmt.Printf("%d records from %s to %s\n\n", len(records), records[0].Timestamp.Local().Format("2006-01-02 15:04:05"), records[len(records)-1].Timestamp.Local().Format("2006-01-02 15:04:05"))
+		table := newTable(os.Stdout, tw.AlignLeft, tw.AlignRight, tw.AlignRight, tw.AlignRight, tw.AlignLeft)
+		table.Header("Metric", "Latest", "Delta", "Delta (first)", "Trend")
+		data := make([][]string, 0, len(series))
+		for _, s := range series {
+			data = append(data, []string{
+				s.Name,
+				formatMetricValue(s.Latest(), s.Unit),
+				formatMetricDelta(s.Delta(), s.Unit),
+				formatMetricDelta(s.DeltaFromFirst(), s.Unit),
+				s.Sparkline(),
+			})
+		}
+		if err := table.Bulk(data); err != nil {
+			return err
+		}
+		return table.Render()
+	},
+}
+
+func init() {
+	metricsCmd.AddCommand(metricsHistoryCmd)
+	metricsHistoryCmd.Flags().IntVarP(&historyLimit, "limit", "n", 0, "only display the latest N records (0 means all)")
+	metricsHistoryCmd.Flags().StringVarP(&historyKey, "key", "k", "", "display every recorded value of the metric with the given key")
+}
+
+func formatMetricDelta(v float64, unit string) string {
+	if v > 0 {
+		return "+" + formatMetricValue(v, unit)
+	}
+	return formatMetricValue(v, unit)
+}
@@ -0,0 +1,240 @@
+/*
+Copyright © 2025 Tailor Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/k1LoW/duration"
+	"github.com/olekukonko/tablewriter/tw"
+	"github.com/spf13/cobra"
+	"github.com/tailor-platform/patterner/tailor"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "plan the migration from the deprecated features",
+	Long:  `plan the migration from the deprecated features of the resources in the workspace.`,
+}
+
+var migrateCELCmd = &cobra.Command{
+	Use:   "cel",
+	Short: "translate the CEL scripts and hooks into JavaScript hooks",
+	Long: `translate the CEL scripts of the pipelines (pre_script, post_script, pre_validation, post_validation)
+and the CEL hooks of the TailorDB fields (create_expr, update_expr) in the workspace into JavaScript hooks,
+and report the expressions that need manual work.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWorkspaces(cmd, runMigrateCEL)
+	},
+}
+
+func runMigrateCEL(ctx context.Context, r *workspaceRun) error {
+	resources, err := fetchResources(ctx, r, tailor.WithoutApplications(), tailor.WithoutStateFlow())
+	if err != nil {
+		return err
+	}
+	migrations := tailor.MigrateCEL(resources)
+	var manual int
+	for _, m := range migrations {
+		fmt.Fprintf(&r.out, "[%s] %s: %s -> %s\n", m.Type, m.Name, m.Field, m.Replacement)
+		fmt.Fprintf(&r.out, "  CEL: %s\n", m.CEL)
+		if m.Err != nil {
+			fmt.Fprintf(&r.out, "  manual: not translated: %v\n", m.Err)
+		} else {
+			fmt.Fprintf(&r.out, "  JS:  %s\n", m.JS)
+		}
+		for _, n := range m.Notes {
+			fmt.Fprintf(&r.out, "  manual: %s\n", n)
+		}
+		if m.NeedsManualWork() {
+			manual++
+		}
+	}
+	fmt.Fprintf(&r.out, "%d CEL expressions, %d translated, %d need manual work\n", len(migrations), len(migrations)-manual, manual)
+	r.summary = append(r.summary,
+		tailor.Metric{Key: "cel_expressions_total", Name: "Total number of CEL expressions", Value: float64(len(migrations))},
+		tailor.Metric{Key: "cel_expressions_manual_total", Name: "Total number of CEL expressions needing manual work", Value: float64(manual)},
+	)
+	return nil
+}
+
+var migrateDraftCmd = &cobra.Command{
+	Use:   "draft",
+	Short: "plan the migration from the draft feature",
+	Long: `list the draft-enabled TailorDB types and the pipeline steps calling their draft mutations
+(appendDraft, confirmDraft, cancelDraft) grouped by resolver, and print a migration checklist in Markdown
+with the operations suggested to replace them.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWorkspaces(cmd, runMigrateDraft)
+	},
+}
+
+func runMigrateDraft(ctx context.Context, r *workspaceRun) error {
+	resources, err := fetchResources(ctx, r, tailor.WithoutApplications(), tailor.WithoutStateFlow())
+	if err != nil {
+		return err
+	}
+	m, err := tailor.MigrateDraft(resources)
+	if err != nil {
+		return err
+	}
+	var usages int
+	for _, dr := range m.Resolvers {
+		usages += len(dr.Usages)
+	}
+	fmt.Fprintln(&r.out, "# Draft Migration Checklist")
+	fmt.Fprintln(&r.out)
+	fmt.Fprintf(&r.out, "%d draft-enabled types, %d draft mutation calls in %d resolvers.\n", len(m.Types), usages, len(m.Resolvers))
+	fmt.Fprintln(&r.out)
+	fmt.Fprintln(&r.out, "## Resolvers")
+	fmt.Fprintln(&r.out)
+	if len(m.Resolvers) == 0 {
+		fmt.Fprintln(&r.out, "No pipeline step calls draft mutations.")
+		fmt.Fprintln(&r.out)
+	}
+	for _, dr := range m.Resolvers {
+		fmt.Fprintf(&r.out, "### `%s/%s`\n\n", dr.Namespace, dr.Name)
+		for _, u := range dr.Usages {
+			fmt.Fprintf(&r.out, "- [ ] Step `%s`: replace `%s` with `%s` (%s)\n", u.Step, u.Mutation, u.Replacement, u.Suggestion)
+		}
+		fmt.Fprintln(&r.out)
+	}
+	fmt.Fprintln(&r.out, "## Types")
+	fmt.Fprintln(&r.out)
+	if len(m.Types) == 0 {
+		fmt.Fprintln(&r.out, "No type has the draft enabled.")
+	}
+	for _, t := range m.Types {
+		fmt.Fprintf(&r.out, "- [ ] Disable the draft of `%s/%s` after migrating its %d draft mutation calls\n", t.Namespace, t.Name, t.Usages)
+	}
+	r.summary = append(r.summary,
+		tailor.Metric{Key: "draft_types_total", Name: "Total number of draft-enabled types", Value: float64(len(m.Types))},
+		tailor.Metric{Key: "draft_mutation_calls_total", Name: "Total number of draft mutation calls", Value: float64(usages)},
+	)
+	return nil
+}
+
+var migrateStateFlowCmd = &cobra.Command{
+	Use:   "stateflow",
+	Short: "report the impact of removing StateFlow",
+	Long: `report the StateFlow namespaces with their admin users and the pipeline steps calling newState or moveState
+with their numbers of recent executions, to plan and prioritize the removal of StateFlow.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWorkspaces(cmd, runMigrateStateFlow)
+	},
+}
+
+func runMigrateStateFlow(ctx context.Context, r *workspaceRun) error {
+	d, err := duration.Parse(since)
+	if err != nil {
+		return err
+	}
+	s := time.Now().Add(-d)
+	resources, err := fetchResources(ctx, r, tailor.WithExecutionResults(&s), tailor.WithoutApplications(), tailor.WithoutTailorDB())
+	if err != nil {
+		return err
+	}
+	m, err := tailor.MigrateStateFlow(resources)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(&r.out, "StateFlow namespaces")
+	fmt.Fprintln(&r.out, "============================================================")
+	table := newTable(&r.out, tw.AlignLeft, tw.AlignLeft)
+	table.Header("Namespace", "Admin users")
+	data := make([][]string, 0, len(m.Namespaces))
+	for _, ns := range m.Namespaces {
+		data = append(data, []string{ns.Name, strings.Join(ns.AdminUsers, ", ")})
+	}
+	if err := table.Bulk(data); err != nil {
+		return err
+	}
+	if err := table.Render(); err != nil {
+		return err
+	}
+	fmt.Fprintln(&r.out)
+
+	fmt.Fprintf(&r.out, "Pipeline steps calling newState or moveState (executions in the last %s)\n", since)
+	fmt.Fprintln(&r.out, "============================================================")
+	table = newTable(&r.out, tw.AlignLeft, tw.AlignLeft, tw.AlignRight, tw.AlignRight)
+	table.Header("Step", "Mutation", "Executions", "Failed")
+	data = make([][]string, 0, len(m.Usages))
+	var executions int
+	for _, u := range m.Usages {
+		data = append(data, []string{
+			fmt.Sprintf("%s/%s step %s", u.Namespace, u.Resolver, u.Step),
+			u.Mutation,
+			fmt.Sprintf("%d", u.Executions),
+			fmt.Sprintf("%d", u.FailedExecutions),
+		})
+		executions += u.Executions
+	}
+	if err := table.Bulk(data); err != nil {
+		return err
+	}
+	if err := table.Render(); err != nil {
+		return err
+	}
+	r.summary = append(r.summary,
+		tailor.Metric{Key: "stateflow_namespaces_total", Name: "Total number of StateFlow namespaces", Value: float64(len(m.Namespaces))},
+		tailor.Metric{Key: "stateflow_mutation_calls_total", Name: "Total number of StateFlow mutation calls", Value: float64(len(m.Usages))},
+		tailor.Metric{Key: "stateflow_mutation_executions_total", Name: "Total number of executions of the steps calling StateFlow mutations", Value: float64(executions)},
+	)
+	return nil
+}
+
+// fetchResources fetches the resources of the workspace with the options, following the --refresh and --keep-going flags.
+func fetchResources(ctx context.Context, r *workspaceRun, opts ...tailor.ResourceOption) (*tailor.Resources, error) {
+	c, err := tailor.New(r.cfg)
+	if err != nil {
+		return nil, err
+	}
+	if keepGoing {
+		opts = append(opts, tailor.WithKeepGoing())
+	}
+	if refresh {
+		opts = append(opts, tailor.WithRefresh())
+	}
+	resources, err := c.Resources(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	printFetchErrors(&r.errOut, resources)
+	return resources, nil
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(migrateCELCmd)
+	migrateCmd.AddCommand(migrateDraftCmd)
+	migrateCmd.AddCommand(migrateStateFlowCmd)
+	migrateCmd.PersistentFlags().BoolVarP(&refresh, "refresh", "", false, "ignore the cached resources and refetch all of them")
+	migrateStateFlowCmd.Flags().StringVarP(&since, "since", "s", "30min", "only consider executions since the given duration (e.g., 24hours, 30min, 15sec)")
+	migrateCmd.PersistentFlags().BoolVarP(&keepGoing, "keep-going", "", false, "continue with the resources that could be fetched when some namespaces or resources cannot be fetched")
+}
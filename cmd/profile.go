@@ -0,0 +1,170 @@
+/*
+Copyright © 2025 Tailor Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/k1LoW/duration"
+	"github.com/olekukonko/tablewriter/tw"
+	"github.com/spf13/cobra"
+	"github.com/tailor-platform/patterner/tailor"
+)
+
+var slowestFinalSteps int
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "profile the execution time of the pipeline resolvers",
+	Long: `profile the execution time of the pipeline resolvers using the execution results.
+
+The API does not report the latency of each step, only the creation and the last update of each execution.
+The latency of a whole execution is therefore attributed to the step where it ended (its last executed step):
+the "Slowest final steps" table ranks the steps where the executions stopped, not the steps by their own cost.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spi.Start()
+		defer spi.Stop()
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if workspaceID != "" {
+			cfg.WorkspaceID = workspaceID
+		}
+		applyFilterFlags(cfg)
+		c, err := tailor.New(cfg)
+		if err != nil {
+			return err
+		}
+		ctx, _, done, err := startTelemetry(cmd, cfg)
+		if err != nil {
+			return err
+		}
+		defer done()
+		d, err := duration.Parse(since)
+		if err != nil {
+			return err
+		}
+		s := time.Now().Add(-d)
+		opts := []tailor.ResourceOption{
+			tailor.WithExecutionResults(&s),
+			tailor.WithoutApplications(),
+			tailor.WithoutStateFlow(),
+			tailor.WithoutTailorDB(),
+		}
+		if keepGoing {
+			opts = append(opts, tailor.WithKeepGoing())
+		}
+		if refresh {
+			opts = append(opts, tailor.WithRefresh())
+		}
+		resources, err := c.Resources(ctx, opts...)
+		if err != nil {
+			return err
+		}
+		spi.Disable()
+		printFetchErrors(os.Stderr, resources)
+		profiles, err := c.Profile(resources)
+		if err != nil {
+			return err
+		}
+		slices.SortStableFunc(profiles, func(a, b *tailor.ResolverProfile) int {
+			return cmp.Compare(b.Latency.P95, a.Latency.P95)
+		})
+
+		fmt.Println("Resolvers")
+		fmt.Println("============================================================")
+		table := newTable(os.Stdout, tw.AlignLeft, tw.AlignRight, tw.AlignRight, tw.AlignRight, tw.AlignRight, tw.AlignRight, tw.AlignRight, tw.AlignRight)
+		table.Header("Resolver", "Calls", "Calls/min", "Error rate", "p50", "p95", "p99", "Max")
+		data := make([][]string, 0, len(profiles))
+		for _, rp := range profiles {
+			if rp.Calls == 0 {
+				continue
+			}
+			data = append(data, []string{
+				fmt.Sprintf("%s/%s", rp.PipelineNamespaceName, rp.Name),
+				fmt.Sprintf("%d", rp.Calls),
+				callsPerMinute(rp.Calls, d),
+				fmt.Sprintf("%.1f%%", rp.ErrorRate()),
+				formatLatency(rp.Latency.P50),
+				formatLatency(rp.Latency.P95),
+				formatLatency(rp.Latency.P99),
+				formatLatency(rp.Latency.Max),
+			})
+		}
+		if err := table.Bulk(data); err != nil {
+			return err
+		}
+		if err := table.Render(); err != nil {
+			return err
+		}
+		fmt.Println()
+
+		fmt.Println("Slowest final steps")
+		fmt.Println("============================================================")
+		table = newTable(os.Stdout, tw.AlignLeft, tw.AlignRight, tw.AlignRight, tw.AlignRight, tw.AlignRight)
+		table.Header("Final step", "Executions", "Error rate", "p95", "Max")
+		steps := tailor.SlowestFinalSteps(profiles, slowestFinalSteps)
+		data = make([][]string, 0, len(steps))
+		for _, sp := range steps {
+			data = append(data, []string{
+				fmt.Sprintf("%s/%s step %s", sp.PipelineNamespaceName, sp.ResolverName, sp.Name),
+				fmt.Sprintf("%d", sp.Calls),
+				fmt.Sprintf("%.1f%%", sp.ErrorRate()),
+				formatLatency(sp.Latency.P95),
+				formatLatency(sp.Latency.Max),
+			})
+		}
+		if err := table.Bulk(data); err != nil {
+			return err
+		}
+		return table.Render()
+	},
+}
+
+// callsPerMinute formats the rate of the calls in the window, which is unknown for an empty window (e.g., --since 0).
+func callsPerMinute(calls int, d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", float64(calls)/d.Minutes())
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.Flags().BoolVarP(&refresh, "refresh", "", false, "ignore the cached resources and refetch all of them")
+	profileCmd.Flags().BoolVarP(&keepGoing, "keep-going", "", false, "continue with the resources that could be fetched when some namespaces or resources cannot be fetched")
+	profileCmd.Flags().StringVarP(&since, "since", "s", "30min", "only consider executions since the given duration (e.g., 24hours, 30min, 15sec)")
+	profileCmd.Flags().IntVarP(&slowestFinalSteps, "top", "n", 10, "number of slowest final steps to display")
+}
+
+func formatLatency(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.String()
+	}
+	return d.Round(time.Millisecond).String()
+}
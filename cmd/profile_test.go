@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCallsPerMinute(t *testing.T) {
+	tests := []struct {
+		calls int
+		d     time.Duration
+		want  string
+	}{
+		{30, 10 * time.Minute, "3.0"},
+		{0, time.Hour, "0.0"},
+		{5, 0, "-"},
+		{5, -time.Minute, "-"},
+	}
+	for _, tt := range tests {
+		if got := callsPerMinute(tt.calls, tt.d); got != tt.want {
+			t.Errorf("callsPerMinute(%d, %v) = %s, want %s", tt.calls, tt.d, got, tt.want)
+		}
+	}
+}
@@ -22,17 +22,39 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
+	"github.com/olekukonko/tablewriter"
+	"github.com/olekukonko/tablewriter/renderer"
+	"github.com/olekukonko/tablewriter/tw"
 	"github.com/spf13/cobra"
+	"github.com/tailor-platform/patterner/config"
+	"github.com/tailor-platform/patterner/tailor"
+	"github.com/tailor-platform/patterner/telemetry"
 	"github.com/tailor-platform/patterner/version"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
-	workspaceID string
-	spi         = spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(os.Stderr))
+	workspaceID  string
+	otlpEndpoint string
+	profile      string
+	sets         []string
+	tokenFile    string
+	keepGoing    bool
+	refresh      bool
+	namespaces   []string
+	resolvers    []string
+	types        []string
+	spi          = spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(os.Stderr))
 )
 
 var rootCmd = &cobra.Command{
@@ -51,4 +73,125 @@ func Execute() {
 
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&workspaceID, "workspace-id", "w", "", "Workspace ID (required)")
+	rootCmd.PersistentFlags().StringSliceVarP(&namespaces, "namespace", "", nil, "only process the namespaces matching the glob (prefix with ! to exclude, can be repeated)")
+	rootCmd.PersistentFlags().StringSliceVarP(&resolvers, "resolver", "", nil, "only process the resolvers matching the glob (prefix with ! to exclude, can be repeated)")
+	rootCmd.PersistentFlags().StringSliceVarP(&types, "type", "", nil, "only process the TailorDB types matching the glob (prefix with ! to exclude, can be repeated)")
+	rootCmd.PersistentFlags().StringVarP(&profile, "profile", "", "", "profile of the configuration file to use (e.g., ci, local)")
+	rootCmd.PersistentFlags().StringArrayVarP(&sets, "set", "", nil, "override the setting of the configuration (e.g., lint.rules.pipeline.stepCount.max=20, can be repeated)")
+	rootCmd.PersistentFlags().StringVarP(&tokenFile, "token-file", "", "", "path of the file containing the access token")
+	rootCmd.PersistentFlags().StringVarP(&otlpEndpoint, "otlp-endpoint", "", "", "OTLP/HTTP endpoint to export traces and metrics to (e.g., http://localhost:4318)")
+}
+
+// loadConfig loads the configuration file with the profile and the overrides given by the flags.
+func loadConfig() (*config.Config, error) {
+	return config.Load(loadOptions()...)
+}
+
+// loadOptions returns the options to load the configuration with the profile and the overrides given by the flags.
+func loadOptions() []config.LoadOption {
+	opts := []config.LoadOption{config.WithSet(sets...)}
+	if tokenFile != "" {
+		// As an override, the token file applies to every workspace.
+		opts = append(opts, config.WithSet("auth.tokenFile="+tokenFile))
+	}
+	if profile != "" {
+		opts = append(opts, config.WithProfile(profile))
+	}
+	return opts
+}
+
+// applyFilterFlags adds the patterns given by the filter flags to the filter of the configuration.
+func applyFilterFlags(cfg *config.Config) {
+	addPatterns(&cfg.Filter.Namespaces, namespaces)
+	addPatterns(&cfg.Filter.Resolvers, resolvers)
+	addPatterns(&cfg.Filter.Types, types)
+}
+
+func addPatterns(p *config.Patterns, patterns []string) {
+	for _, pattern := range patterns {
+		if exclude, ok := strings.CutPrefix(pattern, "!"); ok {
+			p.Exclude = append(p.Exclude, exclude)
+			continue
+		}
+		p.Include = append(p.Include, pattern)
+	}
+}
+
+// printFetchErrors prints the resources that could not be fetched in keep-going mode.
+func printFetchErrors(w io.Writer, resources *tailor.Resources) {
+	for _, fe := range resources.FetchErrors {
+		fmt.Fprintf(w, "warning: %v\n", fe)
+	}
+}
+
+// startTelemetry starts tracing the command when an OTLP endpoint is configured.
+// It returns the context to run the command with, the endpoint (nil when disabled) and a function to flush the telemetry.
+func startTelemetry(cmd *cobra.Command, cfg *config.Config) (context.Context, *telemetry.Endpoint, func(), error) {
+	if otlpEndpoint != "" {
+		cfg.Telemetry.OTLPEndpoint = otlpEndpoint
+	}
+	if cfg.Telemetry.OTLPEndpoint == "" {
+		return cmd.Context(), nil, func() {}, nil
+	}
+	endpoint, err := telemetry.ParseEndpoint(cfg.Telemetry.OTLPEndpoint)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	shutdown, err := telemetry.StartTracing(cmd.Context(), endpoint)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ctx, span := otel.Tracer("github.com/tailor-platform/patterner/cmd").Start(cmd.Context(), cmd.CommandPath(),
+		trace.WithAttributes(attribute.String("tailor.workspace_id", cfg.WorkspaceID)))
+	return ctx, endpoint, func() {
+		span.End()
+		sctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := shutdown(sctx); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to export traces: %v\n", err)
+		}
+	}, nil
+}
+
+// newTable returns a borderless table with the given column alignments.
+func newTable(w io.Writer, aligns ...tw.Align) *tablewriter.Table {
+	return tablewriter.NewTable(w,
+		tablewriter.WithTrimSpace(tw.Off),
+		tablewriter.WithRenderer(renderer.NewBlueprint(tw.Rendition{
+			Borders: tw.BorderNone,
+			Symbols: tw.NewSymbols(tw.StyleNone),
+			Settings: tw.Settings{
+				Lines: tw.Lines{
+					ShowTop:        tw.Off,
+					ShowBottom:     tw.Off,
+					ShowHeaderLine: tw.Off,
+					ShowFooterLine: tw.Off,
+				},
+				Separators: tw.Separators{
+					ShowHeader:     tw.Off,
+					ShowFooter:     tw.Off,
+					BetweenRows:    tw.Off,
+					BetweenColumns: tw.Off,
+				},
+			},
+		})),
+		tablewriter.WithHeaderConfig(tw.CellConfig{
+			Formatting: tw.CellFormatting{
+				AutoFormat: tw.Off,
+				Alignment:  tw.AlignLeft,
+			},
+			Padding: tw.CellPadding{
+				Global: tw.Padding{Left: tw.Space, Right: tw.Space, Top: tw.Empty, Bottom: tw.Empty},
+			},
+		}),
+		tablewriter.WithRowConfig(tw.CellConfig{
+			Formatting: tw.CellFormatting{
+				AutoFormat: tw.Off,
+			},
+			ColumnAligns: aligns,
+			Padding: tw.CellPadding{
+				Global: tw.Padding{Left: tw.Space, Right: tw.Space, Top: tw.Empty, Bottom: tw.Empty},
+			},
+		}),
+	)
 }
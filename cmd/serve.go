@@ -0,0 +1,118 @@
+/*
+Copyright © 2025 Tailor Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/k1LoW/duration"
+	"github.com/spf13/cobra"
+	"github.com/tailor-platform/patterner/exporter"
+	"github.com/tailor-platform/patterner/tailor"
+)
+
+var (
+	listen   string
+	interval string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "serve the metrics in OpenMetrics format",
+	Long:  `serve the metrics about the resources in the specified workspace in OpenMetrics format for Prometheus.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if workspaceID != "" {
+			cfg.WorkspaceID = workspaceID
+		}
+		applyFilterFlags(cfg)
+		c, err := tailor.New(cfg)
+		if err != nil {
+			return err
+		}
+		d, err := duration.Parse(since)
+		if err != nil {
+			return err
+		}
+		i, err := duration.Parse(interval)
+		if err != nil {
+			return err
+		}
+		if i <= 0 {
+			return errors.New("interval must be positive")
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		var opts []tailor.ResourceOption
+		if keepGoing {
+			opts = append(opts, tailor.WithKeepGoing())
+		}
+		if refresh {
+			opts = append(opts, tailor.WithRefresh())
+		}
+		e := exporter.New(c, cfg.WorkspaceID, i, d, opts...)
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", e)
+		srv := &http.Server{
+			Addr:              listen,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go e.Run(ctx, func(err error) {
+			fmt.Fprintf(os.Stderr, "%s %v\n", time.Now().Format(time.RFC3339), err)
+		})
+		go func() {
+			<-ctx.Done()
+			sctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_ = srv.Shutdown(sctx)
+		}()
+
+		fmt.Fprintf(os.Stderr, "Serving metrics on %s/metrics (refresh interval: %s)\n", listen, i)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVarP(&listen, "listen", "l", ":9090", "address to listen on")
+	serveCmd.Flags().StringVarP(&interval, "interval", "i", "5min", "interval to refresh the metrics (e.g., 5min, 1hour)")
+	serveCmd.Flags().StringVarP(&since, "since", "s", "30min", "only consider executions since the given duration (e.g., 24hours, 30min, 15sec)")
+	serveCmd.Flags().BoolVarP(&keepGoing, "keep-going", "", false, "continue with the resources that could be fetched when some namespaces or resources cannot be fetched")
+	serveCmd.Flags().BoolVarP(&refresh, "refresh", "", false, "ignore the cached resources and refetch all of them at every refresh")
+}
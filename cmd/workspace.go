@@ -0,0 +1,246 @@
+/*
+Copyright © 2025 Tailor Inc.
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/olekukonko/tablewriter/tw"
+	"github.com/spf13/cobra"
+	"github.com/tailor-platform/patterner/config"
+	"github.com/tailor-platform/patterner/tailor"
+	"github.com/tailor-platform/patterner/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// workspaceRun is a run of a command against a workspace.
+type workspaceRun struct {
+	// name is the name of the workspace in the multi-workspace mode, and empty otherwise.
+	name     string
+	cfg      *config.Config
+	endpoint *telemetry.Endpoint
+	// out and errOut buffer the report of the run, which is printed after all the runs finish.
+	out    bytes.Buffer
+	errOut bytes.Buffer
+	// summary is the metrics summarizing the run in the aggregate summary.
+	summary []tailor.Metric
+}
+
+// runWorkspaces runs the command against the workspace of the configuration.
+// When the configuration lists workspaces and no workspace ID is given, it runs the command against all of them concurrently,
+// prints the reports grouped by workspace followed by an aggregate summary, and fails if any workspace fails.
+func runWorkspaces(cmd *cobra.Command, run func(ctx context.Context, r *workspaceRun) error) error {
+	spi.Start()
+	defer spi.Stop()
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	var runs []*workspaceRun
+	if workspaceID != "" || len(cfg.Workspaces) == 0 {
+		if workspaceID != "" {
+			cfg.WorkspaceID = workspaceID
+		}
+		cfg.Workspaces = nil
+		runs = append(runs, &workspaceRun{cfg: cfg})
+	} else {
+		for _, w := range cfg.Workspaces {
+			wc, err := cfg.ForWorkspace(w)
+			if err != nil {
+				return err
+			}
+			runs = append(runs, &workspaceRun{name: w.Name, cfg: wc})
+		}
+	}
+	ctx, endpoint, done, err := startTelemetry(cmd, cfg)
+	if err != nil {
+		return err
+	}
+	defer done()
+	for _, r := range runs {
+		applyFilterFlags(r.cfg)
+		r.endpoint = endpoint
+	}
+
+	if len(runs) == 1 && runs[0].name == "" {
+		r := runs[0]
+		err := run(ctx, r)
+		spi.Disable()
+		r.flush(os.Stdout, os.Stderr)
+		return err
+	}
+
+	errs := make([]error, len(runs))
+	var wg sync.WaitGroup
+	for i, r := range runs {
+		wg.Go(func() {
+			ctx, span := otel.Tracer("github.com/tailor-platform/patterner/cmd").Start(ctx, r.name,
+				trace.WithAttributes(attribute.String("tailor.workspace_id", r.cfg.WorkspaceID)))
+			defer span.End()
+			errs[i] = run(ctx, r)
+		})
+	}
+	wg.Wait()
+	spi.Disable()
+
+	var failed int
+	for i, r := range runs {
+		fmt.Printf("Workspace %s (%s)\n", r.name, r.cfg.WorkspaceID)
+		fmt.Println("============================================================")
+		r.flush(os.Stdout, os.Stderr)
+		if errs[i] != nil {
+			failed++
+			fmt.Printf("Error: %v\n", errs[i])
+		}
+		fmt.Println()
+	}
+	fmt.Println("Summary")
+	fmt.Println("============================================================")
+	if err := printWorkspaceSummary(os.Stdout, runs, errs); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d workspaces failed", failed, len(runs))
+	}
+	return nil
+}
+
+func (r *workspaceRun) flush(out, errOut io.Writer) {
+	_, _ = r.errOut.WriteTo(errOut)
+	_, _ = r.out.WriteTo(out)
+}
+
+// outputPath returns the path of the file to output for the workspace,
+// which has the name of the workspace before the extension in the multi-workspace mode (e.g., metrics.prod.json).
+func (r *workspaceRun) outputPath(path string) string {
+	if r.name == "" {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s.%s%s", strings.TrimSuffix(path, ext), r.name, ext)
+}
+
+// printWorkspaceSummary prints the status and the summary metrics of the workspaces with the aggregate of them.
+// The percentages and the averages are averaged, the maximums are maximized, and the others are summed up.
+func printWorkspaceSummary(w io.Writer, runs []*workspaceRun, errs []error) error {
+	header := []any{""}
+	aligns := []tw.Align{tw.AlignLeft}
+	status := []string{"Status"}
+	for i, r := range runs {
+		header = append(header, r.name)
+		aligns = append(aligns, tw.AlignRight)
+		if errs[i] != nil {
+			status = append(status, "failed")
+		} else {
+			status = append(status, "ok")
+		}
+	}
+	header = append(header, "Total")
+	aligns = append(aligns, tw.AlignRight)
+	var failed int
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	status = append(status, fmt.Sprintf("%d/%d ok", len(runs)-failed, len(runs)))
+
+	var keys []string
+	metrics := map[string]tailor.Metric{}
+	for _, r := range runs {
+		for _, m := range r.summary {
+			if _, ok := metrics[m.Key]; !ok {
+				keys = append(keys, m.Key)
+				metrics[m.Key] = m
+			}
+		}
+	}
+	data := [][]string{status}
+	for _, key := range keys {
+		m := metrics[key]
+		row := []string{m.Name}
+		var values []float64
+		for _, r := range runs {
+			v, ok := summaryValue(r.summary, key)
+			if !ok {
+				row = append(row, "-")
+				continue
+			}
+			values = append(values, v)
+			row = append(row, formatMetricValue(v, m.Unit))
+		}
+		if len(values) == 0 {
+			row = append(row, "-")
+		} else {
+			row = append(row, formatMetricValue(aggregate(m, values), m.Unit))
+		}
+		data = append(data, row)
+	}
+	table := newTable(w, aligns...)
+	table.Header(header...)
+	if err := table.Bulk(data); err != nil {
+		return err
+	}
+	return table.Render()
+}
+
+// summaryValue returns the value of the metric of the key, which is missing if the metric could not be computed.
+func summaryValue(summary []tailor.Metric, key string) (float64, bool) {
+	for _, m := range summary {
+		if m.Key == key && m.Error == nil {
+			return m.Value, true
+		}
+	}
+	return 0, false
+}
+
+func aggregate(m tailor.Metric, values []float64) float64 {
+	switch {
+	case m.Unit == "%" || strings.HasSuffix(m.Key, "_average"):
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values))
+	case strings.HasSuffix(m.Key, "_max"):
+		var maximum float64
+		for _, v := range values {
+			maximum = max(maximum, v)
+		}
+		return maximum
+	default:
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum
+	}
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -9,14 +10,59 @@ import (
 )
 
 type Config struct {
-	WorkspaceID string  `default:"" yaml:"workspaceID,omitempty"`
-	Lint        Lint    `yaml:"lint,omitempty"`
-	Metrics     Metrics `yaml:"metrics,omitempty"`
+	// Extends is the path of the configuration file, or the name of the preset, to extend.
+	Extends     string    `yaml:"extends,omitempty"`
+	WorkspaceID string    `default:"" yaml:"workspaceID,omitempty"`
+	Lint        Lint      `yaml:"lint,omitempty"`
+	Metrics     Metrics   `yaml:"metrics,omitempty"`
+	Coverage    Coverage  `yaml:"coverage,omitempty"`
+	Telemetry   Telemetry `yaml:"telemetry,omitempty"`
+	Auth        Auth      `yaml:"auth,omitempty"`
+	API         API       `yaml:"api,omitempty"`
+	Cache       Cache     `yaml:"cache,omitempty"`
+	Filter      Filter    `yaml:"filter,omitempty"`
+	// Workspaces are the workspaces to run the commands against, each overriding the configuration above.
+	Workspaces []Workspace `yaml:"workspaces,omitempty"`
+	// Profiles are the named overrides of the configuration selected with --profile.
+	Profiles map[string]Profile `yaml:"profiles,omitempty"`
+
+	// path is the path of the loaded configuration file.
+	path string
+	// layers are the contents merged on top of the defaults in order: the extended configurations, the file and the profile.
+	layers [][]byte
+	// overrides are the overrides given by the environment variables and --set, applied after the layers.
+	overrides []override
+}
+
+// Workspace is a workspace of the multi-workspace mode.
+// Other keys of the entry override the configuration of the file for the workspace.
+type Workspace struct {
+	Name        string `yaml:"name"`
+	WorkspaceID string `yaml:"workspaceID"`
+
+	// raw is the content of the entry.
+	raw []byte
+}
+
+// Profile is a named override of the configuration.
+type Profile struct {
+	// raw is the content of the profile.
+	raw []byte
 }
 
 type Lint struct {
 	Acceptable int   `default:"0" yaml:"acceptable,omitempty"`
 	Rules      Rules `yaml:"rules,omitempty,omitzero"`
+	// Ignore are the warnings to ignore, e.g., the existing violations to baseline.
+	Ignore []LintIgnore `yaml:"ignore,omitempty"`
+}
+
+// LintIgnore ignores the warnings of the rule for the resources.
+type LintIgnore struct {
+	// Rule is the rule of the warnings (e.g., pipeline/stepCount).
+	Rule string `yaml:"rule"`
+	// Resource is the glob pattern of the resources in the form of "<namespace>/<name>", or "<namespace>" for StateFlow.
+	Resource string `yaml:"resource"`
 }
 
 type Rules struct {
@@ -31,6 +77,9 @@ type Pipeline struct {
 	StepCount             StepCount                 `yaml:"stepCount,omitempty,omitzero"`
 	MultipleMutations     MultipleMutations         `yaml:"multipleMutations,omitempty,omitzero"`
 	QueryBeforeMutation   QueryBeforeMutation       `yaml:"queryBeforeMutation,omitempty,omitzero"`
+	Complexity            Complexity                `yaml:"complexity,omitempty,omitzero"`
+	MaxDepth              MaxDepth                  `yaml:"maxDepth,omitempty,omitzero"`
+	MaxCost               MaxCost                   `yaml:"maxCost,omitempty,omitzero"`
 }
 
 type PipelineDeprecatedFeature struct {
@@ -57,6 +106,21 @@ type QueryBeforeMutation struct {
 	Enabled bool `default:"true" yaml:"enabled,omitempty"`
 }
 
+type Complexity struct {
+	Enabled bool `default:"false" yaml:"enabled,omitempty"`
+	Max     int  `default:"50" yaml:"max,omitempty"`
+}
+
+type MaxDepth struct {
+	Enabled bool `default:"false" yaml:"enabled,omitempty"`
+	Max     int  `default:"10" yaml:"max,omitempty"`
+}
+
+type MaxCost struct {
+	Enabled bool `default:"false" yaml:"enabled,omitempty"`
+	Max     int  `default:"1000" yaml:"max,omitempty"`
+}
+
 type TailorDB struct {
 	DeprecatedFeature TailorDBDeprecatedFeature `yaml:"deprecatedFeature,omitempty,omitzero"`
 }
@@ -79,10 +143,72 @@ type StateFlowDeprecatedFeature struct {
 
 type Metrics struct {
 	Octocov Octocov `yaml:"octocov,omitempty,omitzero"`
+	History History `yaml:"history,omitempty,omitzero"`
 }
 
 type Octocov struct {
 	Acceptables []string `yaml:"acceptables,omitempty"`
+	// Pipelines and TailorDBs are the acceptables of the metric sets of the pipeline and TailorDB namespaces, keyed by namespace name.
+	// They are separate because a pipeline and a TailorDB namespace may have the same name but have different metrics.
+	Pipelines map[string]OctocovNamespace `yaml:"pipelines,omitempty"`
+	TailorDBs map[string]OctocovNamespace `yaml:"tailordbs,omitempty"`
+}
+
+type OctocovNamespace struct {
+	Acceptables []string `yaml:"acceptables,omitempty"`
+}
+
+type History struct {
+	// Path is resolved relative to the directory of the configuration file (see Config.HistoryPath).
+	Path string `default:".patterner-history.jsonl" yaml:"path,omitempty"`
+}
+
+type Coverage struct {
+	Minimum    float64            `default:"0" yaml:"minimum,omitempty"`
+	Namespaces map[string]float64 `yaml:"namespaces,omitempty"`
+	Resolvers  map[string]float64 `yaml:"resolvers,omitempty"`
+}
+
+type Telemetry struct {
+	OTLPEndpoint string `yaml:"otlpEndpoint,omitempty"`
+}
+
+type Auth struct {
+	// TokenFile is the path of the file containing the access token.
+	TokenFile string `yaml:"tokenFile,omitempty"`
+	// TokenURL is the URL of the OAuth 2.0 token endpoint to log in as a machine user and to refresh tokens.
+	TokenURL string `yaml:"tokenURL,omitempty"`
+	// TailorctlConfig is the path of the tailorctl config file (default: ~/.tailorctl/config).
+	TailorctlConfig string `yaml:"tailorctlConfig,omitempty"`
+	// ClientID is the OAuth 2.0 client ID to log in with patterner login.
+	ClientID string `yaml:"clientID,omitempty"`
+	// DeviceAuthorizationURL is the URL of the OAuth 2.0 device authorization endpoint of patterner login.
+	DeviceAuthorizationURL string `yaml:"deviceAuthorizationURL,omitempty"`
+	// CredentialsFile is the path of the file of the credentials saved by patterner login (default: patterner/credentials.json in the user config directory).
+	CredentialsFile string `yaml:"credentialsFile,omitempty"`
+}
+
+type API struct {
+	Concurrency int    `default:"8" yaml:"concurrency,omitempty"`
+	Timeout     string `default:"30sec" yaml:"timeout,omitempty"`
+	MaxRetries  int    `default:"3" yaml:"maxRetries,omitempty"`
+}
+
+type Cache struct {
+	Enabled bool   `default:"true" yaml:"enabled"`
+	Dir     string `default:"" yaml:"dir,omitempty"`
+	TTL     string `default:"10min" yaml:"ttl,omitempty"`
+}
+
+type Filter struct {
+	Namespaces Patterns `yaml:"namespaces,omitempty,omitzero"`
+	Resolvers  Patterns `yaml:"resolvers,omitempty,omitzero"`
+	Types      Patterns `yaml:"types,omitempty,omitzero"`
+}
+
+type Patterns struct {
+	Include []string `yaml:"include,omitempty"`
+	Exclude []string `yaml:"exclude,omitempty"`
 }
 
 const Filename = ".patterner.yml"
@@ -95,30 +221,211 @@ func New() (*Config, error) {
 	return c, nil
 }
 
-func Load() (*Config, error) {
+// LoadOption is an option of Load.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	profile string
+	sets    []string
+	noFile  bool
+}
+
+// WithProfile selects the profile of the configuration file to merge on top of it.
+func WithProfile(name string) LoadOption {
+	return func(o *loadOptions) {
+		o.profile = name
+	}
+}
+
+// WithoutFile skips reading the configuration file, so that only the defaults and the overrides are applied
+// (e.g., to generate the file).
+func WithoutFile() LoadOption {
+	return func(o *loadOptions) {
+		o.noFile = true
+	}
+}
+
+// Load loads the configuration file found by walking up from the working directory.
+// The defaults, the configurations it extends, the file itself, the selected profile,
+// the environment variables and the key=value pairs are merged in this order.
+func Load(opts ...LoadOption) (*Config, error) {
+	o := &loadOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
 	c, err := New()
 	if err != nil {
 		return nil, err
 	}
-	wd, err := os.Getwd()
+	var path string
+	if !o.noFile {
+		path, err = find()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if path == "" && o.profile != "" {
+		return nil, fmt.Errorf("profile %s is given but %s is not found", o.profile, Filename)
+	}
+	var layers [][]byte
+	if path != "" {
+		layers, err = loadLayers(path, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, b := range layers {
+		if err := yaml.Unmarshal(b, c); err != nil {
+			return nil, err
+		}
+	}
+	if o.profile != "" {
+		p, ok := c.Profiles[o.profile]
+		if !ok {
+			return nil, fmt.Errorf("profile %s is not defined", o.profile)
+		}
+		if err := yaml.Unmarshal(p.raw, c); err != nil {
+			return nil, fmt.Errorf("invalid configuration of profile %s: %w", o.profile, err)
+		}
+		layers = append(layers, p.raw)
+	}
+	ovs, err := overrides(os.LookupEnv, o.sets)
 	if err != nil {
 		return nil, err
 	}
+	if err := c.applyOverrides(ovs); err != nil {
+		return nil, err
+	}
+	c.path = path
+	c.layers = layers
+	c.overrides = ovs
+	if err := c.validateWorkspaces(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Path returns the path of the loaded configuration file, or an empty string if no file is loaded.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// HistoryPath returns the path of the metrics history file.
+// A relative path is resolved against the directory of the configuration file, so that the commands run from
+// a subdirectory share the history. It is resolved against the working directory when no file is loaded.
+func (c *Config) HistoryPath() string {
+	p := c.Metrics.History.Path
+	if c.path == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(filepath.Dir(c.path), p)
+}
+
+// find returns the path of the configuration file found by walking up from the working directory, or an empty string.
+func find() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
 	for {
-		b, err := os.ReadFile(filepath.Join(wd, Filename))
+		path := filepath.Join(wd, Filename)
+		_, err := os.Stat(path)
 		if err == nil {
-			if err := yaml.Unmarshal(b, c); err != nil {
-				return nil, err
-			}
-			return c, nil
+			return path, nil
 		}
 		if !os.IsNotExist(err) {
-			return nil, err
+			return "", err
 		}
 		wd = filepath.Dir(wd)
 		if wd == "/" || wd == "." {
 			break
 		}
 	}
-	return c, nil
+	return "", nil
+}
+
+// ForWorkspace returns the configuration of the workspace, which is the loaded configuration overridden by the entry of the workspace.
+func (c *Config) ForWorkspace(w Workspace) (*Config, error) {
+	wc, err := New()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range c.layers {
+		if err := yaml.Unmarshal(b, wc); err != nil {
+			return nil, err
+		}
+	}
+	if err := yaml.Unmarshal(w.raw, wc); err != nil {
+		return nil, fmt.Errorf("invalid configuration of workspace %s: %w", w.Name, err)
+	}
+	if err := wc.applyOverrides(c.overrides); err != nil {
+		return nil, err
+	}
+	wc.WorkspaceID = w.WorkspaceID
+	wc.Workspaces = nil
+	wc.path = c.path
+	return wc, nil
+}
+
+func (c *Config) validateWorkspaces() error {
+	names := map[string]struct{}{}
+	for i, w := range c.Workspaces {
+		if w.Name == "" {
+			return fmt.Errorf("workspaces[%d]: name is required", i)
+		}
+		if w.WorkspaceID == "" {
+			return fmt.Errorf("workspaces[%d]: workspaceID is required", i)
+		}
+		if _, ok := names[w.Name]; ok {
+			return fmt.Errorf("workspaces[%d]: duplicate name %s", i, w.Name)
+		}
+		names[w.Name] = struct{}{}
+	}
+	return nil
+}
+
+// UnmarshalYAML decodes the entry keeping its content to override the configuration with.
+func (w *Workspace) UnmarshalYAML(b []byte) error {
+	type workspace Workspace
+	var v workspace
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*w = Workspace(v)
+	w.raw = b
+	return nil
+}
+
+// UnmarshalYAML decodes the profile keeping its content to override the configuration with.
+func (p *Profile) UnmarshalYAML(b []byte) error {
+	var v map[string]any
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	p.raw = b
+	return nil
+}
+
+// MarshalYAML encodes the entry as it is written.
+func (w Workspace) MarshalYAML() (any, error) {
+	if w.raw == nil {
+		return yaml.MapSlice{
+			{Key: "name", Value: w.Name},
+			{Key: "workspaceID", Value: w.WorkspaceID},
+		}, nil
+	}
+	var v yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions(w.raw, &v, yaml.UseOrderedMap()); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// MarshalYAML encodes the profile as it is written.
+func (p Profile) MarshalYAML() (any, error) {
+	v := yaml.MapSlice{}
+	if err := yaml.UnmarshalWithOptions(p.raw, &v, yaml.UseOrderedMap()); err != nil {
+		return nil, err
+	}
+	return v, nil
 }
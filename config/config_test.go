@@ -0,0 +1,311 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad_Extends(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "shared", "base.yml"), `extends: strict
+lint:
+  acceptable: 3
+coverage:
+  minimum: 70
+`)
+	writeFile(t, filepath.Join(dir, "repo", Filename), `extends: ../shared/base.yml
+workspaceID: ws
+coverage:
+  minimum: 60
+profiles:
+  local:
+    lint:
+      acceptable: 10
+`)
+	t.Chdir(filepath.Join(dir, "repo"))
+
+	c, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Lint.Acceptable != 3 {
+		t.Errorf("lint.acceptable: got %d, want 3", c.Lint.Acceptable)
+	}
+	if c.Lint.Rules.Pipeline.StepCount.Max != 20 {
+		t.Errorf("stepCount.max from preset: got %d, want 20", c.Lint.Rules.Pipeline.StepCount.Max)
+	}
+	if c.Coverage.Minimum != 60 {
+		t.Errorf("coverage.minimum: got %v, want 60", c.Coverage.Minimum)
+	}
+	if !c.Cache.Enabled {
+		t.Error("cache.enabled: default should be kept")
+	}
+
+	c, err = Load(WithProfile("local"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Lint.Acceptable != 10 {
+		t.Errorf("lint.acceptable with profile: got %d, want 10", c.Lint.Acceptable)
+	}
+	if c.Lint.Rules.Pipeline.StepCount.Max != 20 {
+		t.Errorf("stepCount.max with profile: got %d, want 20", c.Lint.Rules.Pipeline.StepCount.Max)
+	}
+
+	if _, err := Load(WithProfile("ci")); err == nil {
+		t.Error("expected error for undefined profile")
+	}
+}
+
+func TestLoad_CircularExtends(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, Filename), "extends: base.yml\n")
+	writeFile(t, filepath.Join(dir, "base.yml"), "extends: .patterner.yml\n")
+	t.Chdir(dir)
+
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "circular extends") {
+		t.Errorf("expected circular extends error, got %v", err)
+	}
+}
+
+func TestConfig_ForWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, Filename), `extends: recommended
+lint:
+  acceptable: 3
+workspaces:
+  - name: prod
+    workspaceID: prod-id
+    lint:
+      rules:
+        pipeline:
+          stepCount:
+            max: 10
+  - name: stg
+    workspaceID: stg-id
+    coverage:
+      minimum: 10
+`)
+	t.Chdir(dir)
+
+	c, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Workspaces) != 2 {
+		t.Fatalf("got %d workspaces, want 2", len(c.Workspaces))
+	}
+	prod, err := c.ForWorkspace(c.Workspaces[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prod.WorkspaceID != "prod-id" || prod.Lint.Acceptable != 3 || prod.Lint.Rules.Pipeline.StepCount.Max != 10 || prod.Coverage.Minimum != 50 {
+		t.Errorf("unexpected prod configuration: %+v", prod)
+	}
+	stg, err := c.ForWorkspace(c.Workspaces[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stg.WorkspaceID != "stg-id" || stg.Lint.Rules.Pipeline.StepCount.Max != 30 || stg.Coverage.Minimum != 10 {
+		t.Errorf("unexpected stg configuration: %+v", stg)
+	}
+	if len(stg.Workspaces) != 0 {
+		t.Error("workspaces should be cleared in the configuration of a workspace")
+	}
+}
+
+func TestConfig_HistoryPath(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, Filename), `workspaces:
+  - name: prod
+    workspaceID: prod-id
+`)
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0700); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(sub)
+
+	c, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(filepath.Dir(c.Path()), ".patterner-history.jsonl")
+	if got := c.HistoryPath(); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	prod, err := c.ForWorkspace(c.Workspaces[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := prod.HistoryPath(); got != want {
+		t.Errorf("got %s for the workspace, want %s", got, want)
+	}
+	abs := filepath.Join(t.TempDir(), "history.jsonl")
+	c.Metrics.History.Path = abs
+	if got := c.HistoryPath(); got != abs {
+		t.Errorf("got %s, want %s", got, abs)
+	}
+}
+
+func TestLoad_UnknownKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, Filename), `lint:
+  rules:
+    pipeline:
+      stepcount:
+        max: 10
+workspaces:
+  - name: prod
+    workspaceID: prod-id
+    covrage:
+      minimum: 10
+profiles:
+  ci:
+    lint:
+      acceptable: 0
+`)
+	t.Chdir(dir)
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for unknown keys")
+	}
+	for _, want := range []string{
+		`:4:7: unknown key "lint.rules.pipeline.stepcount" (did you mean "stepCount"?)`,
+		`:9:5: unknown key "workspaces[0].covrage"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "profiles") {
+		t.Errorf("profile keys should be valid: %v", err)
+	}
+}
+
+func TestPresets(t *testing.T) {
+	for _, name := range Presets() {
+		t.Run(name, func(t *testing.T) {
+			b, err := presets.ReadFile("presets/" + name + ".yml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := validate(name, b); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	got, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(filepath.Join("..", "patterner.schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(got)) != strings.TrimSpace(string(want)) {
+		t.Error("patterner.schema.json is outdated, regenerate it with `patterner config schema > patterner.schema.json`")
+	}
+}
+
+func TestConfig_Set(t *testing.T) {
+	c, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range [][2]string{
+		{"lint.rules.pipeline.stepCount.max", "20"},
+		{"lint.acceptable", "3"},
+		{"LINT.RULES.PIPELINE.INSECUREAUTHORIZATION.ENABLED", "false"},
+		{"coverage.minimum", "75.5"},
+		{"coverage.namespaces.my-pipeline", "80"},
+		{"metrics.octocov.pipelines.my-pipeline.acceptables", "current.lint_warnings_total == 0,diff.lint_warnings_total <= 0"},
+		{"metrics.octocov.tailordbs.my-pipeline.acceptables", "current.tailordb_types_total <= 50"},
+		{"filter.namespaces.include", "team-a-*,team-b-*"},
+	} {
+		if err := c.Set(s[0], s[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c.Lint.Rules.Pipeline.StepCount.Max != 20 || c.Lint.Acceptable != 3 || c.Lint.Rules.Pipeline.InsecureAuthorization.Enabled {
+		t.Errorf("unexpected lint configuration: %+v", c.Lint)
+	}
+	if c.Coverage.Minimum != 75.5 || c.Coverage.Namespaces["my-pipeline"] != 80 {
+		t.Errorf("unexpected coverage configuration: %+v", c.Coverage)
+	}
+	if got := c.Metrics.Octocov.Pipelines["my-pipeline"].Acceptables; len(got) != 2 {
+		t.Errorf("unexpected octocov acceptables: %v", got)
+	}
+	if got := c.Metrics.Octocov.TailorDBs["my-pipeline"].Acceptables; len(got) != 1 {
+		t.Errorf("unexpected octocov acceptables: %v", got)
+	}
+	if got := c.Filter.Namespaces.Include; len(got) != 2 || got[1] != "team-b-*" {
+		t.Errorf("unexpected filter: %v", got)
+	}
+
+	for _, key := range []string{"lint.rules.pipeline.stepcount.maximum", "lint.rules", "workspaces", "profiles.ci"} {
+		if err := c.Set(key, "1"); err == nil {
+			t.Errorf("expected error for %s", key)
+		}
+	}
+	if err := c.Set("lint.acceptable", "many"); err == nil {
+		t.Error("expected error for invalid value")
+	}
+}
+
+func TestLoad_Overrides(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, Filename), `lint:
+  acceptable: 3
+workspaces:
+  - name: prod
+    workspaceID: prod-id
+    lint:
+      acceptable: 5
+`)
+	t.Chdir(dir)
+	t.Setenv("PATTERNER_LINT_RULES_PIPELINE_STEPCOUNT_MAX", "15")
+	t.Setenv("PATTERNER_LINT_ACCEPTABLE", "1")
+
+	c, err := Load(WithSet("lint.rules.pipeline.stepCount.max=10"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Lint.Acceptable != 1 || c.Lint.Rules.Pipeline.StepCount.Max != 10 {
+		t.Errorf("unexpected lint configuration: %+v", c.Lint)
+	}
+	prod, err := c.ForWorkspace(c.Workspaces[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prod.Lint.Acceptable != 1 || prod.Lint.Rules.Pipeline.StepCount.Max != 10 || prod.WorkspaceID != "prod-id" {
+		t.Errorf("overrides should apply to the workspace: %+v", prod.Lint)
+	}
+
+	if _, err := Load(WithSet("lint.acceptable")); err == nil {
+		t.Error("expected error for --set without value")
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got, want := EnvName("lint.rules.pipeline.stepCount.max"), "PATTERNER_LINT_RULES_PIPELINE_STEPCOUNT_MAX"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+//go:embed presets/*.yml
+var presets embed.FS
+
+// Presets returns the names of the embedded presets that can be extended.
+func Presets() []string {
+	entries, err := presets.ReadDir("presets")
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())))
+	}
+	return names
+}
+
+// loadLayers returns the contents of the configuration file and the configurations it extends, the most basic first.
+// visited is the paths of the files extending the file, to detect cycles.
+func loadLayers(path string, visited []string) ([][]byte, error) {
+	if slices.Contains(visited, path) {
+		return nil, fmt.Errorf("circular extends: %s", strings.Join(append(visited, path), " -> "))
+	}
+	b, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(path, b); err != nil {
+		return nil, err
+	}
+	var v struct {
+		Extends string `yaml:"extends"`
+	}
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if v.Extends == "" {
+		return [][]byte{b}, nil
+	}
+	if slices.Contains(Presets(), v.Extends) {
+		preset, err := presets.ReadFile("presets/" + v.Extends + ".yml")
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{preset, b}, nil
+	}
+	base := v.Extends
+	if !filepath.IsAbs(base) {
+		base = filepath.Join(filepath.Dir(path), base)
+	}
+	if _, err := os.Stat(base); err != nil {
+		return nil, fmt.Errorf("failed to extend %s from %s (presets: %s): %w", v.Extends, path, strings.Join(Presets(), ", "), err)
+	}
+	layers, err := loadLayers(base, append(visited, path))
+	if err != nil {
+		return nil, err
+	}
+	return append(layers, b), nil
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix is the prefix of the environment variables overriding the settings.
+// The name of the variable is the key of the setting in upper case with the dots replaced by underscores
+// (e.g., PATTERNER_LINT_RULES_PIPELINE_STEPCOUNT_MAX for lint.rules.pipeline.stepCount.max).
+const EnvPrefix = "PATTERNER_"
+
+type override struct {
+	key   string
+	value string
+}
+
+// WithSet overrides the settings with the key=value pairs (e.g., lint.rules.pipeline.stepCount.max=20).
+func WithSet(sets ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.sets = append(o.sets, sets...)
+	}
+}
+
+// overrides returns the overrides given by the environment variables followed by the ones given by the key=value pairs.
+func overrides(lookupEnv func(string) (string, bool), sets []string) ([]override, error) {
+	var ovs []override
+	for _, key := range EnvKeys() {
+		if v, ok := lookupEnv(EnvName(key)); ok {
+			ovs = append(ovs, override{key: key, value: v})
+		}
+	}
+	for _, s := range sets {
+		key, value, ok := strings.Cut(s, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --set value: %s (must be key=value)", s)
+		}
+		ovs = append(ovs, override{key: key, value: value})
+	}
+	return ovs, nil
+}
+
+func (c *Config) applyOverrides(ovs []override) error {
+	for _, ov := range ovs {
+		if err := c.Set(ov.key, ov.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set sets the setting of the key (e.g., lint.rules.pipeline.stepCount.max) to the value.
+// The segments of the key are matched case-insensitively. Lists are given as comma-separated values,
+// and the entries of maps by their keys (e.g., coverage.namespaces.my-pipeline=80).
+func (c *Config) Set(key, value string) error {
+	if err := setValue(reflect.ValueOf(c).Elem(), strings.Split(key, "."), value); err != nil {
+		return fmt.Errorf("failed to set %s: %w", key, err)
+	}
+	return nil
+}
+
+func setValue(v reflect.Value, path []string, value string) error {
+	switch v.Type() {
+	case workspaceType, profileType:
+		return errors.New("workspaces and profiles cannot be set")
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if len(path) == 0 {
+			return errors.New("key is not a setting")
+		}
+		for _, f := range structFields(v.Type()) {
+			if strings.EqualFold(f.name, path[0]) {
+				return setValue(v.FieldByIndex(f.index), path[1:], value)
+			}
+		}
+		return fmt.Errorf("unknown key %q", path[0])
+	case reflect.Map:
+		if len(path) == 0 {
+			return errors.New("key is not a setting, set the entries of the map instead")
+		}
+		if v.Type().Elem() == profileType {
+			return errors.New("workspaces and profiles cannot be set")
+		}
+		key := reflect.ValueOf(path[0])
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if current := v.MapIndex(key); current.IsValid() {
+			elem.Set(current)
+		}
+		if err := setValue(elem, path[1:], value); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+	}
+	if len(path) > 0 {
+		return fmt.Errorf("unknown key %q", path[0])
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return errors.New("lists of objects cannot be set")
+		}
+		var values []string
+		if value != "" {
+			values = strings.Split(value, ",")
+		}
+		v.Set(reflect.ValueOf(values))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		v.SetString(value)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// EnvKeys returns the keys of the settings that can be overridden by the environment variables.
+// The entries of maps and the lists of objects such as the workspaces cannot be.
+func EnvKeys() []string {
+	return envKeys(configType, "")
+}
+
+func envKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for _, f := range structFields(t) {
+		key := joinKey(prefix, f.name)
+		switch {
+		case f.name == "extends" && t == configType:
+		case f.typ.Kind() == reflect.Struct:
+			keys = append(keys, envKeys(f.typ, key)...)
+		case f.typ.Kind() == reflect.Map:
+		case f.typ.Kind() == reflect.Slice && f.typ.Elem().Kind() != reflect.String:
+		default:
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// EnvName returns the name of the environment variable overriding the setting of the key.
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
@@ -0,0 +1,270 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// SchemaID is the ID of the JSON Schema of the configuration file.
+const SchemaID = "https://raw.githubusercontent.com/tailor-platform/patterner/main/patterner.schema.json"
+
+var (
+	configType    = reflect.TypeFor[Config]()
+	workspaceType = reflect.TypeFor[Workspace]()
+	profileType   = reflect.TypeFor[Profile]()
+)
+
+type field struct {
+	name  string
+	typ   reflect.Type
+	tag   reflect.StructTag
+	index []int
+}
+
+// fieldsOf returns the YAML fields of the struct type.
+// A workspace has the fields of the configuration to override besides its own, and a profile has only the fields of the configuration.
+func fieldsOf(t reflect.Type) []field {
+	switch t {
+	case workspaceType:
+		return append(structFields(workspaceType), slices.DeleteFunc(structFields(configType), func(f field) bool {
+			return f.name == "workspaceID" || f.name == "workspaces" || f.name == "profiles" || f.name == "extends"
+		})...)
+	case profileType:
+		return slices.DeleteFunc(structFields(configType), func(f field) bool {
+			return f.name == "profiles" || f.name == "extends"
+		})
+	}
+	return structFields(t)
+}
+
+func structFields(t reflect.Type) []field {
+	var fields []field
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		fields = append(fields, field{name: name, typ: sf.Type, tag: sf.Tag, index: sf.Index})
+	}
+	return fields
+}
+
+func isStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct || t == workspaceType || t == profileType
+}
+
+// validate reports the unknown keys of the configuration file with their positions.
+func validate(path string, b []byte) error {
+	f, err := parser.ParseBytes(b, 0)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	var errs []error
+	for _, doc := range f.Docs {
+		if doc.Body == nil {
+			continue
+		}
+		errs = append(errs, validateNode(path, doc.Body, configType, "")...)
+	}
+	return errors.Join(errs...)
+}
+
+func validateNode(path string, node ast.Node, t reflect.Type, keyPath string) []error {
+	switch n := node.(type) {
+	case *ast.TagNode:
+		return validateNode(path, n.Value, t, keyPath)
+	case *ast.AnchorNode:
+		return validateNode(path, n.Value, t, keyPath)
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var errs []error
+	switch {
+	case isStruct(t):
+		fields := fieldsOf(t)
+		for _, mv := range mappingValues(node) {
+			key := keyString(mv.Key)
+			i := slices.IndexFunc(fields, func(f field) bool { return f.name == key })
+			if i < 0 {
+				pos := mv.Key.GetToken().Position
+				msg := fmt.Sprintf("%s:%d:%d: unknown key %q", path, pos.Line, pos.Column, joinKey(keyPath, key))
+				if j := slices.IndexFunc(fields, func(f field) bool { return strings.EqualFold(f.name, key) }); j >= 0 {
+					msg += fmt.Sprintf(" (did you mean %q?)", fields[j].name)
+				}
+				errs = append(errs, errors.New(msg))
+				continue
+			}
+			errs = append(errs, validateNode(path, mv.Value, fields[i].typ, joinKey(keyPath, key))...)
+		}
+	case t.Kind() == reflect.Map:
+		for _, mv := range mappingValues(node) {
+			errs = append(errs, validateNode(path, mv.Value, t.Elem(), joinKey(keyPath, keyString(mv.Key)))...)
+		}
+	case t.Kind() == reflect.Slice:
+		if seq, ok := node.(*ast.SequenceNode); ok {
+			for i, v := range seq.Values {
+				errs = append(errs, validateNode(path, v, t.Elem(), fmt.Sprintf("%s[%d]", keyPath, i))...)
+			}
+		}
+	}
+	return errs
+}
+
+func mappingValues(node ast.Node) []*ast.MappingValueNode {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		return n.Values
+	case *ast.MappingValueNode:
+		return []*ast.MappingValueNode{n}
+	}
+	return nil
+}
+
+func keyString(key ast.MapKeyNode) string {
+	if s, ok := key.(ast.ScalarNode); ok {
+		return fmt.Sprint(s.GetValue())
+	}
+	return key.String()
+}
+
+func joinKey(keyPath, key string) string {
+	if keyPath == "" {
+		return key
+	}
+	return keyPath + "." + key
+}
+
+// JSONSchema returns the JSON Schema of the configuration file.
+func JSONSchema() ([]byte, error) {
+	s := schemaOf(configType, "")
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	s["$id"] = SchemaID
+	s["title"] = "Patterner configuration"
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func schemaOf(t reflect.Type, def string) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	s := map[string]any{}
+	switch {
+	case isStruct(t):
+		props := map[string]any{}
+		for _, f := range fieldsOf(t) {
+			props[f.name] = schemaOf(f.typ, f.tag.Get("default"))
+		}
+		s["type"] = "object"
+		s["properties"] = props
+		s["additionalProperties"] = false
+		if t == workspaceType {
+			s["required"] = []string{"name", "workspaceID"}
+		}
+		return s
+	case t.Kind() == reflect.Map:
+		s["type"] = "object"
+		s["additionalProperties"] = schemaOf(t.Elem(), "")
+		return s
+	case t.Kind() == reflect.Slice:
+		s["type"] = "array"
+		s["items"] = schemaOf(t.Elem(), "")
+		return s
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		s["type"] = "boolean"
+		if v, err := strconv.ParseBool(def); err == nil {
+			s["default"] = v
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s["type"] = "integer"
+		if v, err := strconv.Atoi(def); err == nil {
+			s["default"] = v
+		}
+	case reflect.Float32, reflect.Float64:
+		s["type"] = "number"
+		if v, err := strconv.ParseFloat(def, 64); err == nil {
+			s["default"] = v
+		}
+	default:
+		s["type"] = "string"
+		if def != "" {
+			s["default"] = def
+		}
+	}
+	return s
+}
+
+// Effective returns the configuration with every setting including the zero values, which are omitted when marshaling the configuration.
+func (c *Config) Effective() (yaml.MapSlice, error) {
+	v, err := effectiveValue(reflect.ValueOf(c).Elem())
+	if err != nil {
+		return nil, err
+	}
+	return v.(yaml.MapSlice), nil
+}
+
+func effectiveValue(v reflect.Value) (any, error) {
+	switch v.Type() {
+	case workspaceType, profileType:
+		return v.Interface().(yaml.InterfaceMarshaler).MarshalYAML()
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return effectiveValue(v.Elem())
+	case reflect.Struct:
+		var m yaml.MapSlice
+		for _, f := range structFields(v.Type()) {
+			fv, err := effectiveValue(v.FieldByIndex(f.index))
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, yaml.MapItem{Key: f.name, Value: fv})
+		}
+		return m, nil
+	case reflect.Map:
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(fmt.Sprint(a), fmt.Sprint(b)) })
+		m := yaml.MapSlice{}
+		for _, k := range keys {
+			mv, err := effectiveValue(v.MapIndex(k))
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, yaml.MapItem{Key: fmt.Sprint(k), Value: mv})
+		}
+		return m, nil
+	case reflect.Slice:
+		s := []any{}
+		for i := range v.Len() {
+			ev, err := effectiveValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, ev)
+		}
+		return s, nil
+	}
+	return v.Interface(), nil
+}
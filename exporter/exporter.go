@@ -0,0 +1,305 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tailor-platform/patterner/tailor"
+)
+
+const (
+	prefix      = "patterner_"
+	contentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// Exporter periodically collects the metrics of a workspace and exposes them in OpenMetrics format.
+type Exporter struct {
+	client      *tailor.Client
+	workspaceID string
+	interval    time.Duration
+	since       time.Duration
+	opts        []tailor.ResourceOption
+
+	mu                 sync.RWMutex
+	families           []*family
+	lastScrapeError    error
+	lastScrapeTime     time.Time
+	lastScrapeDuration time.Duration
+}
+
+type family struct {
+	name    string
+	help    string
+	unit    string
+	samples []*sample
+}
+
+type sample struct {
+	labels [][2]string
+	value  float64
+}
+
+// New returns an exporter fetching the resources with the options (e.g., tailor.WithKeepGoing) at every collection.
+func New(client *tailor.Client, workspaceID string, interval, since time.Duration, opts ...tailor.ResourceOption) *Exporter {
+	return &Exporter{
+		client:      client,
+		workspaceID: workspaceID,
+		interval:    interval,
+		since:       since,
+		opts:        opts,
+	}
+}
+
+// Run collects the metrics every interval until the context is canceled.
+// onError is called with the error of a failed collection and with the fetch errors skipped in keep-going mode.
+func (e *Exporter) Run(ctx context.Context, onError func(error)) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		if err := e.Collect(ctx, onError); err != nil && ctx.Err() == nil {
+			onError(fmt.Errorf("failed to refresh the metrics: %w", err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Collect fetches the resources and replaces the exposed metrics.
+// On failure, the previously collected metrics are kept and the error is exposed as patterner_last_scrape_error.
+// onFetchError is called with the fetch errors skipped in keep-going mode.
+func (e *Exporter) Collect(ctx context.Context, onFetchError func(error)) error {
+	start := time.Now()
+	families, err := e.collect(ctx, onFetchError)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastScrapeError = err
+	e.lastScrapeTime = start
+	e.lastScrapeDuration = time.Since(start)
+	if err == nil {
+		e.families = families
+	}
+	return err
+}
+
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	if err := e.Write(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Write writes the collected metrics in OpenMetrics text format.
+func (e *Exporter) Write(w io.Writer) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	var b strings.Builder
+	for _, f := range e.families {
+		writeFamily(&b, f)
+	}
+	var scrapeError float64
+	if e.lastScrapeError != nil {
+		scrapeError = 1
+	}
+	labels := [][2]string{{"workspace_id", e.workspaceID}}
+	writeFamily(&b, &family{
+		name:    prefix + "last_scrape_error",
+		help:    "Whether the last collection of the metrics failed (1 for error, 0 for success)",
+		samples: []*sample{{labels: labels, value: scrapeError}},
+	})
+	if !e.lastScrapeTime.IsZero() {
+		writeFamily(&b, &family{
+			name:    prefix + "last_scrape_timestamp_seconds",
+			help:    "Unix time of the last collection of the metrics",
+			unit:    "seconds",
+			samples: []*sample{{labels: labels, value: float64(e.lastScrapeTime.UnixNano()) / 1e9}},
+		})
+		writeFamily(&b, &family{
+			name:    prefix + "last_scrape_duration_seconds",
+			help:    "Duration of the last collection of the metrics",
+			unit:    "seconds",
+			samples: []*sample{{labels: labels, value: e.lastScrapeDuration.Seconds()}},
+		})
+	}
+	b.WriteString("# EOF\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (e *Exporter) collect(ctx context.Context, onFetchError func(error)) ([]*family, error) {
+	s := time.Now().Add(-e.since)
+	opts := append([]tailor.ResourceOption{tailor.WithExecutionResults(&s)}, e.opts...)
+	resources, err := e.client.Resources(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	for _, fe := range resources.FetchErrors {
+		onFetchError(fe)
+	}
+	return e.buildFamilies(resources)
+}
+
+// buildFamilies converts the metrics of the resources into metric families.
+func (e *Exporter) buildFamilies(resources *tailor.Resources) ([]*family, error) {
+	metrics, err := e.client.Metrics(resources)
+	if err != nil {
+		return nil, err
+	}
+	warns, err := e.client.Lint(resources)
+	if err != nil {
+		return nil, err
+	}
+	coverages, err := e.client.Coverage(resources)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := [][2]string{{"workspace_id", e.workspaceID}}
+	var families []*family
+	for _, m := range metrics {
+		if m.Error != nil {
+			continue
+		}
+		f := &family{
+			// The _total suffix is reserved for counters in OpenMetrics, and the metrics are gauges.
+			name:    prefix + strings.TrimSuffix(m.Key, "_total"),
+			help:    m.Name,
+			samples: []*sample{{labels: labels, value: m.Value}},
+		}
+		if m.Unit == "%" {
+			f.unit = "percentage"
+		}
+		families = append(families, f)
+	}
+
+	// Lint warnings by rule
+	byRule := &family{
+		name: prefix + "lint_rule_warnings",
+		help: "Number of lint warnings by rule",
+	}
+	counts := map[tailor.LintRule]int{}
+	types := map[tailor.LintRule]tailor.LintTargetType{}
+	for _, w := range warns {
+		counts[w.Rule]++
+		types[w.Rule] = w.Type
+	}
+	for _, rule := range sortedKeys(counts) {
+		byRule.samples = append(byRule.samples, &sample{
+			labels: append(slices.Clone(labels), [2]string{"type", string(types[rule])}, [2]string{"rule", string(rule)}),
+			value:  float64(counts[rule]),
+		})
+	}
+	families = append(families, byRule)
+
+	// Pipeline namespaces
+	resolvers := &family{
+		name: prefix + "pipeline_namespace_resolvers",
+		help: "Number of Pipeline resolvers by namespace",
+	}
+	steps := &family{
+		name: prefix + "pipeline_namespace_steps",
+		help: "Number of Pipeline resolver steps by namespace",
+	}
+	for _, p := range resources.Pipelines {
+		var stepsTotal int
+		for _, r := range p.Resolvers {
+			stepsTotal += len(r.Steps)
+		}
+		nsLabels := append(slices.Clone(labels), [2]string{"namespace", p.NamespaceName})
+		resolvers.samples = append(resolvers.samples, &sample{labels: nsLabels, value: float64(len(p.Resolvers))})
+		steps.samples = append(steps.samples, &sample{labels: nsLabels, value: float64(stepsTotal)})
+	}
+	families = append(families, resolvers, steps)
+
+	coverage := &family{
+		name: prefix + "pipeline_namespace_step_coverage_percentage",
+		help: "Pipeline resolver step coverage by namespace",
+		unit: "percentage",
+	}
+	total := map[string]int{}
+	covered := map[string]int{}
+	for _, rc := range coverages {
+		total[rc.PipelineNamespaceName] += rc.TotalSteps
+		covered[rc.PipelineNamespaceName] += rc.CoveredSteps
+	}
+	for _, ns := range sortedKeys(total) {
+		var v float64
+		if total[ns] > 0 {
+			v = float64(covered[ns]) / float64(total[ns]) * 100
+		}
+		coverage.samples = append(coverage.samples, &sample{
+			labels: append(slices.Clone(labels), [2]string{"namespace", ns}),
+			value:  v,
+		})
+	}
+	families = append(families, coverage)
+
+	// TailorDB namespaces
+	dbTypes := &family{
+		name: prefix + "tailordb_namespace_types",
+		help: "Number of TailorDB types by namespace",
+	}
+	for _, db := range resources.TailorDBs {
+		dbTypes.samples = append(dbTypes.samples, &sample{
+			labels: append(slices.Clone(labels), [2]string{"namespace", db.NamespaceName}),
+			value:  float64(len(db.Types)),
+		})
+	}
+	families = append(families, dbTypes)
+
+	for _, f := range families {
+		slices.SortStableFunc(f.samples, func(a, b *sample) int {
+			return strings.Compare(labelString(a.labels), labelString(b.labels))
+		})
+	}
+
+	return families, nil
+}
+
+func writeFamily(b *strings.Builder, f *family) {
+	name := f.name
+	if f.unit != "" && !strings.HasSuffix(name, "_"+f.unit) {
+		name = name + "_" + f.unit
+	}
+	fmt.Fprintf(b, "# TYPE %s gauge\n", name)
+	if f.unit != "" {
+		fmt.Fprintf(b, "# UNIT %s %s\n", name, f.unit)
+	}
+	fmt.Fprintf(b, "# HELP %s %s\n", name, escape(f.help))
+	for _, s := range f.samples {
+		fmt.Fprintf(b, "%s%s %s\n", name, labelString(s.labels), strconv.FormatFloat(s.value, 'g', -1, 64))
+	}
+}
+
+func labelString(labels [][2]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(labels))
+	for _, l := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", l[0], escape(l[1])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(s)
+}
+
+func sortedKeys[K ~string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,85 @@
+package exporter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tailorv1 "buf.build/gen/go/tailor-inc/tailor/protocolbuffers/go/tailor/v1"
+	"github.com/tailor-platform/patterner/config"
+	"github.com/tailor-platform/patterner/tailor"
+)
+
+func TestExporter_Write(t *testing.T) {
+	cfg, err := config.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.WorkspaceID = "test-workspace-id"
+	c, err := tailor.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources := &tailor.Resources{
+		Pipelines: []*tailor.Pipeline{
+			{
+				NamespaceName: "ns1",
+				Resolvers: []*tailor.PipelineResolver{
+					{
+						Name:          "resolver1",
+						Authorization: "true",
+						Steps: []*tailor.PipelineStep{
+							{Name: "step1", Operation: tailor.PipelineStepOperation{Type: tailorv1.PipelineResolver_OPERATION_TYPE_FUNCTION}},
+							{Name: "step2", Operation: tailor.PipelineStepOperation{Type: tailorv1.PipelineResolver_OPERATION_TYPE_FUNCTION}},
+						},
+					},
+				},
+			},
+		},
+		StateFlows: []*tailor.StateFlow{
+			{NamespaceName: "sf"},
+		},
+	}
+
+	e := New(c, cfg.WorkspaceID, 0, 0)
+	families, err := e.buildFamilies(resources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.families = families
+	e.lastScrapeError = errors.New("unavailable")
+
+	var b strings.Builder
+	if err := e.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	got := b.String()
+	for _, want := range []string{
+		"# TYPE patterner_lint_warnings gauge\n",
+		"# HELP patterner_lint_warnings Total number of lint warnings\n",
+		"patterner_lint_warnings{workspace_id=\"test-workspace-id\"} 2\n",
+		"patterner_pipeline_resolvers{workspace_id=\"test-workspace-id\"} 1\n",
+		"# UNIT patterner_pipeline_resolver_step_coverage_percentage percentage\n",
+		"patterner_lint_rule_warnings{workspace_id=\"test-workspace-id\",type=\"pipeline\",rule=\"pipeline/insecureAuthorization\"} 1\n",
+		"patterner_lint_rule_warnings{workspace_id=\"test-workspace-id\",type=\"stateflow\",rule=\"stateflow/deprecatedFeature\"} 1\n",
+		"patterner_pipeline_namespace_resolvers{workspace_id=\"test-workspace-id\",namespace=\"ns1\"} 1\n",
+		"patterner_pipeline_namespace_steps{workspace_id=\"test-workspace-id\",namespace=\"ns1\"} 2\n",
+		"patterner_last_scrape_error{workspace_id=\"test-workspace-id\"} 1\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Exporter.Write() output does not contain %q\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "_total") {
+		t.Errorf("Exporter.Write() output contains a gauge with the _total suffix reserved for counters\n%s", got)
+	}
+	if !strings.HasSuffix(got, "# EOF\n") {
+		t.Errorf("Exporter.Write() output does not end with # EOF\n%s", got)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if got, want := escape("a\"b\\c\nd"), `a\"b\\c\nd`; got != want {
+		t.Errorf("escape() = %q, want %q", got, want)
+	}
+}
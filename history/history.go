@@ -0,0 +1,183 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Record is a snapshot of the metrics of a workspace at a point in time.
+type Record struct {
+	Timestamp   time.Time `json:"timestamp"`
+	WorkspaceID string    `json:"workspace_id"`
+	Version     string    `json:"version,omitempty"`
+	Metrics     []*Metric `json:"metrics"`
+}
+
+type Metric struct {
+	Key   string  `json:"key"`
+	Name  string  `json:"name,omitempty"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit,omitempty"`
+}
+
+// Point is a value of a metric at a point in time.
+type Point struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// Series is the values of a metric over time.
+type Series struct {
+	Key    string
+	Name   string
+	Unit   string
+	Points []*Point
+}
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Append appends the record to the JSON Lines history file, creating it if necessary.
+func Append(path string, r *Record) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return err
+		}
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load reads all records of the workspace from the JSON Lines history file.
+// The workspace ID is required, as the records of different workspaces must not be mixed up.
+// A missing file is treated as an empty history.
+func Load(path, workspaceID string) ([]*Record, error) {
+	if workspaceID == "" {
+		return nil, errors.New("workspace ID is required to load the history")
+	}
+	f, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	var records []*Record
+	s := bufio.NewScanner(f)
+	s.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for s.Scan() {
+		line++
+		if len(s.Bytes()) == 0 {
+			continue
+		}
+		r := &Record{}
+		if err := json.Unmarshal(s.Bytes(), r); err != nil {
+			return nil, fmt.Errorf("failed to parse %s line %d: %w", path, line, err)
+		}
+		if r.WorkspaceID != workspaceID {
+			continue
+		}
+		records = append(records, r)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// SeriesOf converts the records into one series per metric key, in order of first appearance.
+func SeriesOf(records []*Record) []*Series {
+	var series []*Series
+	index := map[string]*Series{}
+	for _, r := range records {
+		for _, m := range r.Metrics {
+			s, ok := index[m.Key]
+			if !ok {
+				s = &Series{Key: m.Key}
+				index[m.Key] = s
+				series = append(series, s)
+			}
+			s.Name = m.Name
+			s.Unit = m.Unit
+			s.Points = append(s.Points, &Point{
+				Timestamp: r.Timestamp,
+				Value:     m.Value,
+			})
+		}
+	}
+	return series
+}
+
+// Latest returns the latest value of the series.
+func (s *Series) Latest() float64 {
+	if len(s.Points) == 0 {
+		return 0
+	}
+	return s.Points[len(s.Points)-1].Value
+}
+
+// Delta returns the difference between the latest value and the previous value.
+func (s *Series) Delta() float64 {
+	if len(s.Points) < 2 {
+		return 0
+	}
+	return s.Points[len(s.Points)-1].Value - s.Points[len(s.Points)-2].Value
+}
+
+// DeltaFromFirst returns the difference between the latest value and the first value.
+func (s *Series) DeltaFromFirst() float64 {
+	if len(s.Points) < 2 {
+		return 0
+	}
+	return s.Points[len(s.Points)-1].Value - s.Points[0].Value
+}
+
+// Sparkline renders the values of the series as a sparkline.
+func (s *Series) Sparkline() string {
+	values := make([]float64, 0, len(s.Points))
+	for _, p := range s.Points {
+		values = append(values, p.Value)
+	}
+	return Sparkline(values)
+}
+
+// Sparkline renders the values as a sparkline.
+func Sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	line := make([]rune, 0, len(values))
+	for _, v := range values {
+		if hi == lo {
+			line = append(line, sparks[len(sparks)/2])
+			continue
+		}
+		i := int(math.Round((v - lo) / (hi - lo) * float64(len(sparks)-1)))
+		line = append(line, sparks[i])
+	}
+	return string(line)
+}
@@ -0,0 +1,84 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppendAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history", "metrics.jsonl")
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []*Record{
+		{Timestamp: base, WorkspaceID: "ws1", Metrics: []*Metric{{Key: "lint_warnings_total", Value: 10}}},
+		{Timestamp: base.Add(time.Hour), WorkspaceID: "ws2", Metrics: []*Metric{{Key: "lint_warnings_total", Value: 3}}},
+		{Timestamp: base.Add(2 * time.Hour), WorkspaceID: "ws1", Metrics: []*Metric{{Key: "lint_warnings_total", Value: 7}}},
+	}
+	for _, r := range records {
+		if err := Append(path, r); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	got, err := Load(path, "ws1")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Load() got %d records, want 2", len(got))
+	}
+	if !got[1].Timestamp.Equal(base.Add(2 * time.Hour)) {
+		t.Errorf("Load() got timestamp %v, want %v", got[1].Timestamp, base.Add(2*time.Hour))
+	}
+
+	if _, err := Load(path, ""); err == nil {
+		t.Error("Load() without workspace ID should fail not to mix the workspaces")
+	}
+
+	missing, err := Load(filepath.Join(t.TempDir(), "missing.jsonl"), "ws1")
+	if err != nil {
+		t.Errorf("Load() of missing file error = %v", err)
+	}
+	if len(missing) != 0 {
+		t.Errorf("Load() of missing file got %d records, want 0", len(missing))
+	}
+}
+
+func TestSeriesOf(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []*Record{
+		{Timestamp: base, Metrics: []*Metric{{Key: "a", Value: 10}}},
+		{Timestamp: base.Add(time.Hour), Metrics: []*Metric{{Key: "a", Value: 6}, {Key: "b", Value: 1}}},
+		{Timestamp: base.Add(2 * time.Hour), Metrics: []*Metric{{Key: "a", Value: 4}, {Key: "b", Value: 2}}},
+	}
+	series := SeriesOf(records)
+	if len(series) != 2 {
+		t.Fatalf("SeriesOf() got %d series, want 2", len(series))
+	}
+	a := series[0]
+	if a.Key != "a" || a.Latest() != 4 || a.Delta() != -2 || a.DeltaFromFirst() != -6 {
+		t.Errorf("series a: key=%s latest=%v delta=%v deltaFromFirst=%v", a.Key, a.Latest(), a.Delta(), a.DeltaFromFirst())
+	}
+	b := series[1]
+	if len(b.Points) != 2 || b.DeltaFromFirst() != 1 {
+		t.Errorf("series b: points=%d deltaFromFirst=%v", len(b.Points), b.DeltaFromFirst())
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   string
+	}{
+		{nil, ""},
+		{[]float64{5}, "▅"},
+		{[]float64{3, 3, 3}, "▅▅▅"},
+		{[]float64{0, 7}, "▁█"},
+		{[]float64{0, 1, 2, 3, 4, 5, 6, 7}, "▁▂▃▄▅▆▇█"},
+	}
+	for _, tt := range tests {
+		if got := Sparkline(tt.values); got != tt.want {
+			t.Errorf("Sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package tailor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/tailor-platform/patterner/config"
+)
+
+// Environment variables of the credentials.
+const (
+	envToken                   = "TAILOR_TOKEN"
+	envMachineUserClientID     = "TAILOR_PLATFORM_MACHINE_USER_CLIENT_ID"
+	envMachineUserClientSecret = "TAILOR_PLATFORM_MACHINE_USER_CLIENT_SECRET"
+)
+
+// tokenExpiryDelta is how long before the expiry a token is renewed.
+const tokenExpiryDelta = time.Minute
+
+// tokenRequestTimeout is the timeout of a request to the OAuth 2.0 endpoints.
+const tokenRequestTimeout = 30 * time.Second
+
+// tokenHTTPClient is the client of the OAuth 2.0 endpoints.
+var tokenHTTPClient = &http.Client{Timeout: tokenRequestTimeout}
+
+// ErrNoCredential is returned when no credential to call the API with is found.
+var ErrNoCredential = fmt.Errorf("no credential found: set %s, give a token file with --token-file, set %s and %s of a machine user, or log in with `patterner login` or `tailorctl auth login`",
+	envToken, envMachineUserClientID, envMachineUserClientSecret)
+
+// tokenSource provides the access token to call the API with.
+type tokenSource struct {
+	// name describes the source in errors.
+	name string
+	// fetch returns a token and its expiry, which is zero if unknown.
+	// renew is true when the token fetched before has expired or been rejected.
+	fetch func(ctx context.Context, renew bool) (string, time.Time, error)
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// Token returns the cached token, fetching a new one when it is missing or expires soon.
+func (s *tokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && (s.expiry.IsZero() || time.Until(s.expiry) > tokenExpiryDelta) {
+		return s.token, nil
+	}
+	return s.renew(ctx)
+}
+
+// Refresh returns a new token after the API rejected the token.
+// When another request has already refreshed it, the refreshed token is returned.
+func (s *tokenSource) Refresh(ctx context.Context, rejected string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && s.token != rejected {
+		return s.token, nil
+	}
+	return s.renew(ctx)
+}
+
+func (s *tokenSource) renew(ctx context.Context) (string, error) {
+	token, expiry, err := s.fetch(ctx, s.token != "")
+	if err != nil {
+		return "", fmt.Errorf("failed to get the access token from %s: %w", s.name, err)
+	}
+	s.token = token
+	s.expiry = expiry
+	return token, nil
+}
+
+// tokenEndpoint returns the URL of the OAuth 2.0 token endpoint of the platform.
+func tokenEndpoint(cfg config.Auth, baseURL string) string {
+	if cfg.TokenURL != "" {
+		return cfg.TokenURL
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/oauth2/token"
+}
+
+// newTokenSource returns the source of the first credential found in the order of
+// the token file, the TAILOR_TOKEN environment variable, the machine user client credentials,
+// the credential saved by patterner login and the tailorctl config file.
+// It returns nil when no credential is found.
+func newTokenSource(cfg config.Auth, baseURL string) (*tokenSource, error) {
+	tokenURL := tokenEndpoint(cfg, baseURL)
+	if cfg.TokenFile != "" {
+		return tokenFileSource(cfg.TokenFile), nil
+	}
+	if token := os.Getenv(envToken); token != "" {
+		return &tokenSource{
+			name: envToken,
+			fetch: func(ctx context.Context, renew bool) (string, time.Time, error) {
+				if renew {
+					return "", time.Time{}, errors.New("the token is rejected")
+				}
+				return token, time.Time{}, nil
+			},
+		}, nil
+	}
+	clientID, clientSecret := os.Getenv(envMachineUserClientID), os.Getenv(envMachineUserClientSecret)
+	if clientID != "" || clientSecret != "" {
+		if clientID == "" || clientSecret == "" {
+			return nil, fmt.Errorf("both %s and %s are required", envMachineUserClientID, envMachineUserClientSecret)
+		}
+		return &tokenSource{
+			name: "the machine user client credentials",
+			fetch: func(ctx context.Context, renew bool) (string, time.Time, error) {
+				res, err := requestToken(ctx, tokenURL, url.Values{
+					"grant_type":    {"client_credentials"},
+					"client_id":     {clientID},
+					"client_secret": {clientSecret},
+				})
+				if err != nil {
+					return "", time.Time{}, err
+				}
+				return res.AccessToken, res.expiry(), nil
+			},
+		}, nil
+	}
+	if path, err := credentialsPath(cfg); err == nil {
+		src, err := loginSource(path, baseURL, tokenURL)
+		if err != nil || src != nil {
+			return src, err
+		}
+	}
+	path := cfg.TailorctlConfig
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil //nolint:nilerr
+		}
+		path = filepath.Join(home, ".tailorctl", "config")
+	}
+	return tailorctlSource(path)
+}
+
+// tokenFileSource reads the token from the file, rereading it when the token is rejected as the file may have been rotated.
+func tokenFileSource(path string) *tokenSource {
+	var last string
+	return &tokenSource{
+		name: path,
+		fetch: func(ctx context.Context, renew bool) (string, time.Time, error) {
+			b, err := os.ReadFile(path) //nolint:gosec
+			if err != nil {
+				return "", time.Time{}, err
+			}
+			token := strings.TrimSpace(string(b))
+			if token == "" {
+				return "", time.Time{}, errors.New("the token file is empty")
+			}
+			if renew && token == last {
+				return "", time.Time{}, errors.New("the token is rejected")
+			}
+			last = token
+			return token, time.Time{}, nil
+		},
+	}
+}
+
+// tailorctlSource reads the credential of the current context of the tailorctl config file.
+// The token is never refreshed on behalf of tailorctl: the token endpoint may rotate the refresh token,
+// which would invalidate the one tailorctl keeps. The user logs in again with tailorctl instead.
+// The file is TOML with a section per context and the name of the current context in the global section.
+// It returns nil when the file does not exist, cannot be read or parsed, or has no credential,
+// so that a broken tailorctl config does not fail the other commands.
+func tailorctlSource(path string) (*tokenSource, error) {
+	var contexts map[string]map[string]any
+	if _, err := toml.DecodeFile(path, &contexts); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "warning: ignoring the credential of tailorctl, failed to read %s: %v\n", path, err)
+		}
+		return nil, nil
+	}
+	current, _ := contexts["global"]["context"].(string)
+	if current == "" {
+		current = "default"
+	}
+	tc := contexts[current]
+	accessToken, _ := tc["controlplaneaccesstoken"].(string)
+	if accessToken == "" {
+		return nil, nil
+	}
+	var expiry time.Time
+	switch v := tc["controlplanetokenexpiresat"].(type) {
+	case time.Time:
+		expiry = v
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			expiry = t
+		}
+	}
+	return &tokenSource{
+		name: path,
+		fetch: func(ctx context.Context, renew bool) (string, time.Time, error) {
+			if renew || (!expiry.IsZero() && time.Until(expiry) <= tokenExpiryDelta) {
+				return "", time.Time{}, errors.New("the token has expired, run a tailorctl command to refresh it or log in again with `tailorctl auth login`")
+			}
+			return accessToken, expiry, nil
+		},
+	}, nil
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+func (r *tokenResponse) expiry() time.Time {
+	if r.ExpiresIn <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(time.Duration(r.ExpiresIn) * time.Second)
+}
+
+// oauthError is an error response of the OAuth 2.0 token endpoint.
+type oauthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+	status      string
+}
+
+func (e *oauthError) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("token endpoint returned %s: %s", e.status, e.Code)
+	}
+	return fmt.Sprintf("token endpoint returned %s: %s: %s", e.status, e.Code, e.Description)
+}
+
+// requestToken requests a token to the OAuth 2.0 token endpoint.
+func requestToken(ctx context.Context, tokenURL string, form url.Values) (*tokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	res, err := tokenHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		oe := &oauthError{status: res.Status}
+		if err := json.Unmarshal(b, oe); err != nil || oe.Code == "" {
+			return nil, fmt.Errorf("token endpoint returned %s: %s", res.Status, strings.TrimSpace(string(b)))
+		}
+		return nil, oe
+	}
+	var tr tokenResponse
+	if err := json.Unmarshal(b, &tr); err != nil {
+		return nil, fmt.Errorf("failed to parse the token response: %w", err)
+	}
+	if tr.AccessToken == "" {
+		return nil, errors.New("token endpoint returned no access token")
+	}
+	return &tr, nil
+}
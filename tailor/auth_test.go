@@ -0,0 +1,388 @@
+package tailor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tailor-platform/patterner/config"
+)
+
+func clearCredentialEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv(envToken, "")
+	t.Setenv(envMachineUserClientID, "")
+	t.Setenv(envMachineUserClientSecret, "")
+	// Isolate the credentials saved by patterner login.
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+}
+
+// newTokenServer returns a token endpoint issuing token-1, token-2, ... and the number of issued tokens.
+func newTokenServer(t *testing.T, wantGrant string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var issued atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		if got := r.PostForm.Get("grant_type"); got != wantGrant {
+			t.Errorf("grant_type: got %s, want %s", got, wantGrant)
+		}
+		n := issued.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  fmt.Sprintf("token-%d", n),
+			"refresh_token": fmt.Sprintf("refresh-%d", n),
+			"expires_in":    3600,
+		})
+	}))
+	t.Cleanup(s.Close)
+	return s, &issued
+}
+
+func TestNewTokenSource_NoCredential(t *testing.T) {
+	clearCredentialEnv(t)
+	src, err := newTokenSource(config.Auth{TailorctlConfig: filepath.Join(t.TempDir(), "config")}, "https://api.tailor.tech")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src != nil {
+		t.Errorf("expected no token source, got %s", src.name)
+	}
+
+	t.Setenv(envMachineUserClientID, "id")
+	if _, err := newTokenSource(config.Auth{}, "https://api.tailor.tech"); err == nil {
+		t.Error("expected error for client ID without secret")
+	}
+}
+
+func TestNewTokenSource_TokenFile(t *testing.T) {
+	clearCredentialEnv(t)
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("file-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	src, err := newTokenSource(config.Auth{TokenFile: path}, "https://api.tailor.tech")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	token, err := src.Token(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "file-token" {
+		t.Errorf("got %s, want file-token", token)
+	}
+	if _, err := src.Refresh(ctx, token); err == nil {
+		t.Error("expected error when the token in the file is rejected")
+	}
+	if err := os.WriteFile(path, []byte("rotated-token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	token, err = src.Refresh(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "rotated-token" {
+		t.Errorf("got %s, want rotated-token", token)
+	}
+}
+
+func TestNewTokenSource_TokenFileOverEnv(t *testing.T) {
+	clearCredentialEnv(t)
+	t.Setenv(envToken, "env-token")
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("file-token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	src, err := newTokenSource(config.Auth{TokenFile: path}, "https://api.tailor.tech")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := src.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "file-token" {
+		t.Errorf("the token file should take precedence over %s: got %s, want file-token", envToken, token)
+	}
+
+	src, err = newTokenSource(config.Auth{}, "https://api.tailor.tech")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err = src.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "env-token" {
+		t.Errorf("got %s, want env-token", token)
+	}
+}
+
+func TestNewTokenSource_Tailorctl(t *testing.T) {
+	clearCredentialEnv(t)
+	server, issued := newTokenServer(t, "refresh_token")
+	path := filepath.Join(t.TempDir(), "config")
+	write := func(expiresAt time.Time) {
+		t.Helper()
+		content := fmt.Sprintf(`[global]
+context = "staging"
+
+[default]
+controlplaneaccesstoken = "default-token"
+
+[staging]
+controlplaneaccesstoken = "staging-token"
+controlplanerefreshtoken = "refresh-0"
+controlplanetokenexpiresat = "%s"
+`, expiresAt.Format(time.RFC3339))
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	cfg := config.Auth{TailorctlConfig: path, TokenURL: server.URL}
+
+	write(time.Now().Add(time.Hour))
+	src, err := newTokenSource(cfg, "https://api.tailor.tech")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := src.Token(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "staging-token" {
+		t.Errorf("got %s, want staging-token", token)
+	}
+	if _, err := src.Refresh(ctx, token); err == nil {
+		t.Error("expected error when the token is rejected")
+	}
+
+	write(time.Now().Add(-time.Hour))
+	src, err = newTokenSource(cfg, "https://api.tailor.tech")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.Token(ctx); err == nil || !strings.Contains(err.Error(), "tailorctl auth login") {
+		t.Errorf("expected error to log in again with tailorctl, got %v", err)
+	}
+	if issued.Load() != 0 {
+		t.Errorf("the token of tailorctl should not be refreshed: %d issued", issued.Load())
+	}
+
+	if err := os.WriteFile(path, []byte("global:\n  context: staging\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	src, err = newTokenSource(cfg, "https://api.tailor.tech")
+	if err != nil {
+		t.Fatalf("an unparsable tailorctl config should be ignored: %v", err)
+	}
+	if src != nil {
+		t.Errorf("expected no token source from an unparsable tailorctl config, got %s", src.name)
+	}
+}
+
+func TestNewTokenSource_Login(t *testing.T) {
+	clearCredentialEnv(t)
+	server, issued := newTokenServer(t, "refresh_token")
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	const baseURL = "https://api.tailor.tech"
+	if err := saveCredential(path, "https://other.example.com", &loginCredential{AccessToken: "other-token"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveCredential(path, baseURL, &loginCredential{
+		AccessToken:  "expired-token",
+		RefreshToken: "refresh-0",
+		Expiry:       time.Now().Add(-time.Hour),
+		ClientID:     "client",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Auth{
+		CredentialsFile: path,
+		TailorctlConfig: filepath.Join(t.TempDir(), "config"),
+		TokenURL:        server.URL,
+	}
+	src, err := newTokenSource(cfg, baseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := src.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "token-1" || issued.Load() != 1 {
+		t.Errorf("expired token should be refreshed: got %s (%d issued)", token, issued.Load())
+	}
+	creds, err := loadCredentials(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := creds[baseURL]; got.AccessToken != "token-1" || got.RefreshToken != "refresh-1" {
+		t.Errorf("the rotated refresh token should be saved: got %+v", got)
+	}
+	if got := creds["https://other.example.com"]; got == nil || got.AccessToken != "other-token" {
+		t.Errorf("the credentials of the other platforms should be kept: got %+v", got)
+	}
+	if info, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("got mode %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestLogin(t *testing.T) {
+	clearCredentialEnv(t)
+	orig := deviceIntervalUnit
+	deviceIntervalUnit = time.Millisecond
+	t.Cleanup(func() { deviceIntervalUnit = orig })
+
+	tests := []struct {
+		name      string
+		responses []string
+		wantErr   bool
+	}{
+		{"approved", []string{"authorization_pending", "slow_down", ""}, false},
+		{"denied", []string{"authorization_pending", "access_denied"}, true},
+		{"expired", []string{"expired_token"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var polls atomic.Int32
+			mux := http.NewServeMux()
+			mux.HandleFunc("/oauth2/device_authorization", func(w http.ResponseWriter, r *http.Request) {
+				if got := r.FormValue("client_id"); got != "client" {
+					t.Errorf("client_id: got %s, want client", got)
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"device_code":      "device-code",
+					"user_code":        "ABCD-EFGH",
+					"verification_uri": "https://example.com/device",
+					"expires_in":       600,
+					"interval":         1,
+				})
+			})
+			mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+				if got := r.FormValue("grant_type"); got != deviceCodeGrantType {
+					t.Errorf("grant_type: got %s, want %s", got, deviceCodeGrantType)
+				}
+				if got := r.FormValue("device_code"); got != "device-code" {
+					t.Errorf("device_code: got %s, want device-code", got)
+				}
+				n := int(polls.Add(1)) - 1
+				if n >= len(tt.responses) {
+					t.Error("polled after the last response")
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				if code := tt.responses[n]; code != "" {
+					w.WriteHeader(http.StatusBadRequest)
+					_ = json.NewEncoder(w).Encode(map[string]any{"error": code})
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"access_token":  "user-token",
+					"refresh_token": "user-refresh",
+					"expires_in":    3600,
+				})
+			})
+			server := httptest.NewServer(mux)
+			t.Cleanup(server.Close)
+			t.Setenv("PLATFORM_URL", server.URL)
+			path := filepath.Join(t.TempDir(), "credentials.json")
+
+			var prompted *DeviceAuthorization
+			got, err := Login(context.Background(), config.Auth{ClientID: "client", CredentialsFile: path}, func(a *DeviceAuthorization) {
+				prompted = a
+			})
+			if prompted == nil || prompted.UserCode != "ABCD-EFGH" || prompted.VerificationURI != "https://example.com/device" {
+				t.Errorf("unexpected prompt: %+v", prompted)
+			}
+			if int(polls.Load()) != len(tt.responses) {
+				t.Errorf("got %d polls, want %d", polls.Load(), len(tt.responses))
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != path {
+				t.Errorf("got %s, want %s", got, path)
+			}
+			creds, err := loadCredentials(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c := creds[server.URL]; c == nil || c.AccessToken != "user-token" || c.RefreshToken != "user-refresh" {
+				t.Errorf("unexpected credential: %+v", c)
+			}
+		})
+	}
+}
+
+func TestBearerTokenTransport_RefreshOnUnauthorized(t *testing.T) {
+	clearCredentialEnv(t)
+	tokenServer, issued := newTokenServer(t, "client_credentials")
+	t.Setenv(envMachineUserClientID, "id")
+	t.Setenv(envMachineUserClientSecret, "secret")
+
+	var calls atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "request" {
+			t.Errorf("body: got %q, want request", body)
+		}
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer api.Close()
+
+	src, err := newTokenSource(config.Auth{TokenURL: tokenServer.URL}, api.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: &bearerTokenTransport{tokens: src, userAgent: "test", base: http.DefaultTransport}}
+	res, err := client.Post(api.URL, "text/plain", strings.NewReader("request"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("status: got %d, want 200", res.StatusCode)
+	}
+	if calls.Load() != 2 || issued.Load() != 2 {
+		t.Errorf("expected one retry with a refreshed token: %d calls, %d tokens issued", calls.Load(), issued.Load())
+	}
+}
+
+func TestClient_Resources_NoCredential(t *testing.T) {
+	clearCredentialEnv(t)
+	cfg := createTestConfig(t)
+	cfg.Auth.TailorctlConfig = filepath.Join(t.TempDir(), "config")
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Resources(context.Background()); err != ErrNoCredential {
+		t.Errorf("got %v, want ErrNoCredential", err)
+	}
+}
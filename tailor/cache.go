@@ -0,0 +1,142 @@
+package tailor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	cacheFileName = "resources.json"
+	cacheVersion  = 1
+)
+
+// resourceCache is the on-disk cache of the resolver and type details of a workspace.
+// An entry is reused while it is younger than the TTL and the list item of the resource has not changed.
+type resourceCache struct {
+	path    string
+	ttl     time.Duration
+	refresh bool
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheFile struct {
+	Version int                    `json:"version"`
+	Entries map[string]*cacheEntry `json:"entries"`
+}
+
+type cacheEntry struct {
+	// Digest is the digest of the list item the detail was fetched for.
+	Digest    string    `json:"digest"`
+	FetchedAt time.Time `json:"fetched_at"`
+	// Detail is the serialized detail of the resource.
+	Detail []byte `json:"detail"`
+	// GQLPermission is whether the TailorDB type has a GraphQL permission.
+	GQLPermission bool `json:"gql_permission,omitempty"`
+}
+
+// loadResourceCache loads the cache of the workspace from the directory.
+// A missing or unreadable cache file is treated as empty, since the cache can always be rebuilt.
+func loadResourceCache(dir, workspaceID string, ttl time.Duration, refresh bool) *resourceCache {
+	c := &resourceCache{
+		path:    filepath.Join(dir, workspaceID, cacheFileName),
+		ttl:     ttl,
+		refresh: refresh,
+		entries: map[string]*cacheEntry{},
+	}
+	b, err := os.ReadFile(c.path)
+	if err != nil {
+		return c
+	}
+	var f cacheFile
+	if err := json.Unmarshal(b, &f); err != nil || f.Version != cacheVersion {
+		return c
+	}
+	for key, e := range f.Entries {
+		if e != nil && !c.expired(e) {
+			c.entries[key] = e
+		}
+	}
+	return c
+}
+
+// get returns the entry of the key if it was cached for the same list item.
+func (c *resourceCache) get(key, digest string) (*cacheEntry, bool) {
+	if c == nil || c.refresh {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || e.Digest != digest || c.expired(e) {
+		return nil, false
+	}
+	return e, true
+}
+
+func (c *resourceCache) put(key string, e *cacheEntry) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = e
+}
+
+// save writes the cache to the file.
+func (c *resourceCache) save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return writeJSONFile(c.path, &cacheFile{
+		Version: cacheVersion,
+		Entries: c.entries,
+	})
+}
+
+func (c *resourceCache) expired(e *cacheEntry) bool {
+	return c.ttl > 0 && time.Since(e.FetchedAt) > c.ttl
+}
+
+// writeJSONFile writes the value as JSON to the file atomically, creating the directory if needed.
+func writeJSONFile(path string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		return errors.Join(err, tmp.Close(), os.Remove(tmp.Name()))
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Join(err, os.Remove(tmp.Name()))
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// digest returns the digest of the serialized message.
+func digest(m proto.Message) (string, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,148 @@
+package tailor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResourceCache(t *testing.T) {
+	dir := t.TempDir()
+	c := loadResourceCache(dir, "test-workspace-id", time.Hour, false)
+	c.put("pipeline/ns/fresh", &cacheEntry{Digest: "d1", FetchedAt: time.Now(), Detail: []byte("fresh")})
+	c.put("tailordb/db/User", &cacheEntry{Digest: "d2", FetchedAt: time.Now(), Detail: []byte("user"), GQLPermission: true})
+	c.put("pipeline/ns/stale", &cacheEntry{Digest: "d3", FetchedAt: time.Now().Add(-2 * time.Hour), Detail: []byte("stale")})
+	if err := c.save(); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "test-workspace-id", cacheFileName)); err != nil {
+		t.Fatalf("cache file not written: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		refresh bool
+		key     string
+		digest  string
+		want    string
+		wantHit bool
+	}{
+		{
+			name:    "hit",
+			key:     "pipeline/ns/fresh",
+			digest:  "d1",
+			want:    "fresh",
+			wantHit: true,
+		},
+		{
+			name:   "list item changed",
+			key:    "pipeline/ns/fresh",
+			digest: "changed",
+		},
+		{
+			name:   "expired",
+			key:    "pipeline/ns/stale",
+			digest: "d3",
+		},
+		{
+			name:   "missing",
+			key:    "pipeline/ns/missing",
+			digest: "d1",
+		},
+		{
+			name:    "refresh",
+			refresh: true,
+			key:     "pipeline/ns/fresh",
+			digest:  "d1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loaded := loadResourceCache(dir, "test-workspace-id", time.Hour, tt.refresh)
+			e, ok := loaded.get(tt.key, tt.digest)
+			if ok != tt.wantHit {
+				t.Fatalf("get() hit = %v, want %v", ok, tt.wantHit)
+			}
+			if ok && string(e.Detail) != tt.want {
+				t.Errorf("get() detail = %q, want %q", e.Detail, tt.want)
+			}
+		})
+	}
+
+	loaded := loadResourceCache(dir, "test-workspace-id", time.Hour, false)
+	e, ok := loaded.get("tailordb/db/User", "d2")
+	if !ok || !e.GQLPermission {
+		t.Errorf("get() = %+v, %v, want entry with GQLPermission", e, ok)
+	}
+	if _, ok := loadResourceCache(dir, "other-workspace-id", time.Hour, false).get("pipeline/ns/fresh", "d1"); ok {
+		t.Error("get() hit the cache of another workspace")
+	}
+}
+
+func TestResourceCache_Corrupted(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test-workspace-id", cacheFileName)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{broken"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c := loadResourceCache(dir, "test-workspace-id", time.Hour, false)
+	if len(c.entries) != 0 {
+		t.Errorf("got %d entries, want 0", len(c.entries))
+	}
+	c.put("pipeline/ns/resolver", &cacheEntry{Digest: "d", FetchedAt: time.Now()})
+	if err := c.save(); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+	if _, ok := loadResourceCache(dir, "test-workspace-id", time.Hour, false).get("pipeline/ns/resolver", "d"); !ok {
+		t.Error("get() missed the entry saved over the corrupted file")
+	}
+}
+
+func TestResourceCache_Nil(t *testing.T) {
+	var c *resourceCache
+	if _, ok := c.get("pipeline/ns/resolver", "d"); ok {
+		t.Error("get() on nil cache hit")
+	}
+	c.put("pipeline/ns/resolver", &cacheEntry{})
+	if err := c.save(); err != nil {
+		t.Errorf("save() on nil cache error = %v", err)
+	}
+}
+
+func TestNew_CacheDir(t *testing.T) {
+	t.Setenv("HOME", "")
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("PLATFORM_URL", "")
+	cfg := createTestConfig(t)
+	cfg.Cache.Enabled = true
+	cfg.Cache.Dir = ""
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() without a user cache directory should fall back: %v", err)
+	}
+	if want := filepath.Join(os.TempDir(), "patterner", "api.tailor.tech"); c.cacheDir != want {
+		t.Errorf("cacheDir = %q, want %q", c.cacheDir, want)
+	}
+
+	cfg.Cache.Dir = t.TempDir()
+	t.Setenv("PLATFORM_URL", "https://api.staging.example.com:8443")
+	staging, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PLATFORM_URL", "")
+	production, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if staging.cacheDir == production.cacheDir {
+		t.Errorf("the caches of different platforms collide in %s", staging.cacheDir)
+	}
+	if want := filepath.Join(cfg.Cache.Dir, "api.staging.example.com_8443"); staging.cacheDir != want {
+		t.Errorf("cacheDir = %q, want %q", staging.cacheDir, want)
+	}
+}
@@ -0,0 +1,860 @@
+package tailor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// CELTranslation is the JavaScript equivalent of a CEL expression.
+type CELTranslation struct {
+	// JS is the JavaScript expression. It is empty when the expression is not translated.
+	JS string
+	// Notes are the differences in semantics the translation may not preserve, to be reviewed by hand.
+	Notes []string
+	// Err is why the expression is not translated.
+	Err error
+}
+
+// NeedsManualWork reports whether the translation is missing or has to be reviewed.
+func (t *CELTranslation) NeedsManualWork() bool {
+	return t.Err != nil || len(t.Notes) > 0
+}
+
+// TranslateCEL converts the CEL expression into the equivalent JavaScript expression.
+// It supports the literals, the variables and their fields, the operators, the string and list functions
+// and the macros (has, all, exists, exists_one, map and filter). The other functions are not translated.
+func TranslateCEL(expr string) *CELTranslation {
+	p, err := newCELParser(expr)
+	if err != nil {
+		return &CELTranslation{Err: err}
+	}
+	n, err := p.parse()
+	if err != nil {
+		return &CELTranslation{Err: err}
+	}
+	t := &celTranslator{}
+	js, err := t.expr(n)
+	if err != nil {
+		return &CELTranslation{Err: err}
+	}
+	if strings.HasPrefix(js.code, "{") {
+		// An object literal at the start of an expression is parsed as a block in JavaScript.
+		js.code = "(" + js.code + ")"
+	}
+	return &CELTranslation{JS: js.code, Notes: t.notes}
+}
+
+type celTokenKind int
+
+const (
+	celEOF celTokenKind = iota
+	celIdent
+	celNumber
+	celString
+	celPunct
+)
+
+type celToken struct {
+	kind celTokenKind
+	// text is the source of the token. For a string, it is the unquoted value.
+	text string
+}
+
+func tokenizeCEL(expr string) ([]celToken, error) {
+	rs := []rune(expr)
+	var tokens []celToken
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+		case r == '/' && i+1 < len(rs) && rs[i+1] == '/':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+		case r == '"' || r == '\'':
+			s, end, err := scanCELString(rs, i, false)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, celToken{celString, s})
+			i = end
+		case (r == 'r' || r == 'R') && i+1 < len(rs) && (rs[i+1] == '"' || rs[i+1] == '\''):
+			s, end, err := scanCELString(rs, i+1, true)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, celToken{celString, s})
+			i = end
+		case (r == 'b' || r == 'B') && i+1 < len(rs) && (rs[i+1] == '"' || rs[i+1] == '\''):
+			return nil, errors.New("bytes literal is not translated")
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i+1 < len(rs) && (unicode.IsLetter(rs[i+1]) || unicode.IsDigit(rs[i+1]) || rs[i+1] == '_') {
+				i++
+			}
+			tokens = append(tokens, celToken{celIdent, string(rs[start : i+1])})
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(rs) && unicode.IsDigit(rs[i+1])):
+			start := i
+			if r == '0' && i+2 < len(rs) && (rs[i+1] == 'x' || rs[i+1] == 'X') {
+				i++
+				for i+1 < len(rs) && strings.ContainsRune("0123456789abcdefABCDEF", rs[i+1]) {
+					i++
+				}
+			} else {
+				for i+1 < len(rs) && unicode.IsDigit(rs[i+1]) {
+					i++
+				}
+				if i+2 < len(rs) && rs[i+1] == '.' && unicode.IsDigit(rs[i+2]) {
+					for i++; i+1 < len(rs) && unicode.IsDigit(rs[i+1]); i++ {
+					}
+				}
+				if i+2 < len(rs) && (rs[i+1] == 'e' || rs[i+1] == 'E') {
+					j := i + 2
+					if rs[j] == '+' || rs[j] == '-' {
+						j++
+					}
+					if j < len(rs) && unicode.IsDigit(rs[j]) {
+						for i = j; i+1 < len(rs) && unicode.IsDigit(rs[i+1]); i++ {
+						}
+					}
+				}
+			}
+			num := string(rs[start : i+1])
+			if i+1 < len(rs) && (rs[i+1] == 'u' || rs[i+1] == 'U') {
+				// Unsigned integers are numbers in JavaScript as well.
+				i++
+			}
+			tokens = append(tokens, celToken{celNumber, num})
+		default:
+			op := string(r)
+			if i+1 < len(rs) {
+				if two := string(rs[i : i+2]); two == "&&" || two == "||" || two == "==" || two == "!=" || two == "<=" || two == ">=" {
+					op = two
+					i++
+				}
+			}
+			if !strings.Contains("&&||==!=<=>=<>!?:.,[]{}()+-*/%", op) || op == "&" || op == "|" || op == "=" {
+				return nil, fmt.Errorf("unexpected character %q", op)
+			}
+			tokens = append(tokens, celToken{celPunct, op})
+		}
+	}
+	return append(tokens, celToken{kind: celEOF}), nil
+}
+
+// scanCELString returns the value of the string literal starting at i and the index of its closing quote.
+func scanCELString(rs []rune, i int, raw bool) (string, int, error) {
+	quote := string(rs[i])
+	if i+2 < len(rs) && rs[i+1] == rs[i] && rs[i+2] == rs[i] {
+		quote = strings.Repeat(quote, 3)
+	}
+	var b strings.Builder
+	for j := i + len(quote); j < len(rs); j++ {
+		if strings.HasPrefix(string(rs[j:min(j+len(quote), len(rs))]), quote) {
+			return b.String(), j + len(quote) - 1, nil
+		}
+		switch {
+		case rs[j] == '\n' && len(quote) == 1:
+			return "", 0, errors.New("unterminated string literal")
+		case rs[j] == '\\' && !raw:
+			if j+1 < len(rs) && (rs[j+1] == '\'' || rs[j+1] == '"' || rs[j+1] == '`' || rs[j+1] == '?') {
+				b.WriteRune(rs[j+1])
+				j++
+				continue
+			}
+			value, _, tail, err := strconv.UnquoteChar(string(rs[j:]), 0)
+			if err != nil {
+				return "", 0, fmt.Errorf("invalid escape sequence in string literal: %w", err)
+			}
+			b.WriteRune(value)
+			j = len(rs) - len([]rune(tail)) - 1
+		default:
+			b.WriteRune(rs[j])
+		}
+	}
+	return "", 0, errors.New("unterminated string literal")
+}
+
+// celNode is a node of the syntax tree of a CEL expression.
+type celNode interface{}
+
+type (
+	celLiteral struct {
+		kind celTokenKind
+		text string
+	}
+	celIdentNode struct{ name string }
+	celSelect    struct {
+		operand celNode
+		field   string
+	}
+	celIndex struct{ operand, index celNode }
+	celCall  struct {
+		// target is the receiver of a method call. It is nil for a global function.
+		target celNode
+		name   string
+		args   []celNode
+	}
+	celUnary struct {
+		op      string
+		operand celNode
+	}
+	celBinary struct {
+		op          string
+		left, right celNode
+	}
+	celConditional struct{ cond, then, els celNode }
+	celList        struct{ elems []celNode }
+	celMap         struct{ keys, values []celNode }
+)
+
+type celParser struct {
+	tokens []celToken
+	pos    int
+}
+
+func newCELParser(expr string) (*celParser, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, errors.New("empty expression")
+	}
+	tokens, err := tokenizeCEL(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &celParser{tokens: tokens}, nil
+}
+
+func (p *celParser) peek() celToken {
+	return p.tokens[p.pos]
+}
+
+func (p *celParser) next() celToken {
+	t := p.tokens[p.pos]
+	if t.kind != celEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *celParser) accept(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != celPunct && !(t.kind == celIdent && t.text == "in") {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *celParser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		return fmt.Errorf("expected %q but found %s", op, p.describe())
+	}
+	return nil
+}
+
+func (p *celParser) describe() string {
+	t := p.peek()
+	if t.kind == celEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+func (p *celParser) parse() (celNode, error) {
+	n, err := p.conditional()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != celEOF {
+		return nil, fmt.Errorf("unexpected %s", p.describe())
+	}
+	return n, nil
+}
+
+func (p *celParser) conditional() (celNode, error) {
+	cond, err := p.binary(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept("?"); !ok {
+		return cond, nil
+	}
+	then, err := p.binary(0)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	els, err := p.conditional()
+	if err != nil {
+		return nil, err
+	}
+	return &celConditional{cond, then, els}, nil
+}
+
+// celBinaryOps are the binary operators of CEL from the lowest precedence.
+var celBinaryOps = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">=", "in"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *celParser) binary(level int) (celNode, error) {
+	if level == len(celBinaryOps) {
+		return p.unary()
+	}
+	left, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(celBinaryOps[level]...)
+		if !ok {
+			return left, nil
+		}
+		right, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &celBinary{op, left, right}
+	}
+}
+
+func (p *celParser) unary() (celNode, error) {
+	if op, ok := p.accept("!", "-"); ok {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &celUnary{op, operand}, nil
+	}
+	return p.member()
+}
+
+func (p *celParser) member() (celNode, error) {
+	n, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch op, _ := p.accept(".", "["); op {
+		case ".":
+			t := p.next()
+			if t.kind != celIdent {
+				return nil, fmt.Errorf("expected a field name but found %q", t.text)
+			}
+			if _, ok := p.accept("("); ok {
+				args, err := p.list(")")
+				if err != nil {
+					return nil, err
+				}
+				n = &celCall{target: n, name: t.text, args: args}
+				continue
+			}
+			n = &celSelect{n, t.text}
+		case "[":
+			index, err := p.conditional()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			n = &celIndex{n, index}
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *celParser) primary() (celNode, error) {
+	t := p.next()
+	switch t.kind {
+	case celNumber, celString:
+		return &celLiteral{t.kind, t.text}, nil
+	case celIdent:
+		if _, ok := p.accept("("); ok {
+			args, err := p.list(")")
+			if err != nil {
+				return nil, err
+			}
+			return &celCall{name: t.text, args: args}, nil
+		}
+		return &celIdentNode{t.text}, nil
+	case celPunct:
+		switch t.text {
+		case "(":
+			n, err := p.conditional()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		case "[":
+			elems, err := p.list("]")
+			if err != nil {
+				return nil, err
+			}
+			return &celList{elems}, nil
+		case "{":
+			return p.mapLiteral()
+		}
+	case celEOF:
+		return nil, errors.New("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
+
+// list parses the comma-separated expressions until the closing token.
+func (p *celParser) list(closing string) ([]celNode, error) {
+	var nodes []celNode
+	for {
+		if _, ok := p.accept(closing); ok {
+			return nodes, nil
+		}
+		n, err := p.conditional()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+		if _, ok := p.accept(","); !ok {
+			return nodes, p.expect(closing)
+		}
+	}
+}
+
+func (p *celParser) mapLiteral() (celNode, error) {
+	m := &celMap{}
+	for {
+		if _, ok := p.accept("}"); ok {
+			return m, nil
+		}
+		key, err := p.conditional()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.conditional()
+		if err != nil {
+			return nil, err
+		}
+		m.keys = append(m.keys, key)
+		m.values = append(m.values, value)
+		if _, ok := p.accept(","); !ok {
+			return m, p.expect("}")
+		}
+	}
+}
+
+// Precedences of the JavaScript operators emitted.
+const (
+	jsArrow = iota + 1
+	jsConditional
+	jsOr
+	jsAnd
+	jsEquality
+	jsRelational
+	jsAdditive
+	jsMultiplicative
+	jsUnary
+	jsMember
+	jsPrimary
+)
+
+type jsExpr struct {
+	code string
+	prec int
+}
+
+// at returns the code of the expression, parenthesized if it binds looser than prec.
+func (e jsExpr) at(prec int) string {
+	if e.prec < prec {
+		return "(" + e.code + ")"
+	}
+	return e.code
+}
+
+var jsBinaryOps = map[string]struct {
+	op   string
+	prec int
+}{
+	"||": {"||", jsOr},
+	"&&": {"&&", jsAnd},
+	"==": {"===", jsEquality},
+	"!=": {"!==", jsEquality},
+	"<":  {"<", jsRelational},
+	"<=": {"<=", jsRelational},
+	">":  {">", jsRelational},
+	">=": {">=", jsRelational},
+	"+":  {"+", jsAdditive},
+	"-":  {"-", jsAdditive},
+	"*":  {"*", jsMultiplicative},
+	"/":  {"/", jsMultiplicative},
+	"%":  {"%", jsMultiplicative},
+}
+
+// jsMethods are the CEL methods with a JavaScript equivalent taking the same arguments.
+var jsMethods = map[string]string{
+	"contains":   "includes",
+	"startsWith": "startsWith",
+	"endsWith":   "endsWith",
+	"lowerAscii": "toLowerCase",
+	"upperAscii": "toUpperCase",
+	"trim":       "trim",
+	"split":      "split",
+	"join":       "join",
+	"replace":    "replaceAll",
+	"substring":  "substring",
+	"indexOf":    "indexOf",
+}
+
+// jsMacros are the CEL macros taking a variable and a predicate, and the JavaScript array methods for them.
+var jsMacros = map[string]string{
+	"all":    "every",
+	"exists": "some",
+	"filter": "filter",
+	"map":    "map",
+}
+
+type celTranslator struct {
+	notes []string
+}
+
+func (t *celTranslator) note(format string, args ...any) {
+	note := fmt.Sprintf(format, args...)
+	for _, n := range t.notes {
+		if n == note {
+			return
+		}
+	}
+	t.notes = append(t.notes, note)
+}
+
+func (t *celTranslator) expr(n celNode) (jsExpr, error) {
+	switch n := n.(type) {
+	case *celLiteral:
+		if n.kind == celString {
+			return jsExpr{jsString(n.text), jsPrimary}, nil
+		}
+		return jsExpr{n.text, jsPrimary}, nil
+	case *celIdentNode:
+		return jsExpr{n.name, jsPrimary}, nil
+	case *celSelect:
+		operand, err := t.expr(n.operand)
+		if err != nil {
+			return jsExpr{}, err
+		}
+		return jsExpr{operand.at(jsMember) + "." + n.field, jsMember}, nil
+	case *celIndex:
+		operand, err := t.expr(n.operand)
+		if err != nil {
+			return jsExpr{}, err
+		}
+		index, err := t.expr(n.index)
+		if err != nil {
+			return jsExpr{}, err
+		}
+		return jsExpr{operand.at(jsMember) + "[" + index.code + "]", jsMember}, nil
+	case *celUnary:
+		operand, err := t.expr(n.operand)
+		if err != nil {
+			return jsExpr{}, err
+		}
+		code := operand.at(jsUnary)
+		if strings.HasPrefix(code, n.op) {
+			// Two minus signs in a row are the decrement operator in JavaScript.
+			code = " " + code
+		}
+		return jsExpr{n.op + code, jsUnary}, nil
+	case *celBinary:
+		return t.binary(n)
+	case *celConditional:
+		cond, err := t.expr(n.cond)
+		if err != nil {
+			return jsExpr{}, err
+		}
+		then, err := t.expr(n.then)
+		if err != nil {
+			return jsExpr{}, err
+		}
+		els, err := t.expr(n.els)
+		if err != nil {
+			return jsExpr{}, err
+		}
+		return jsExpr{cond.at(jsOr) + " ? " + then.at(jsConditional) + " : " + els.at(jsConditional), jsConditional}, nil
+	case *celList:
+		elems, err := t.exprs(n.elems)
+		if err != nil {
+			return jsExpr{}, err
+		}
+		return jsExpr{"[" + strings.Join(elems, ", ") + "]", jsPrimary}, nil
+	case *celMap:
+		entries := make([]string, len(n.keys))
+		for i := range n.keys {
+			key, err := t.expr(n.keys[i])
+			if err != nil {
+				return jsExpr{}, err
+			}
+			value, err := t.expr(n.values[i])
+			if err != nil {
+				return jsExpr{}, err
+			}
+			if l, ok := n.keys[i].(*celLiteral); !ok || l.kind != celString {
+				key.code = "[" + key.code + "]"
+			}
+			entries[i] = key.code + ": " + value.at(jsArrow)
+		}
+		return jsExpr{"{" + strings.Join(entries, ", ") + "}", jsPrimary}, nil
+	case *celCall:
+		return t.call(n)
+	}
+	return jsExpr{}, fmt.Errorf("unexpected node %T", n)
+}
+
+func (t *celTranslator) exprs(nodes []celNode) ([]string, error) {
+	codes := make([]string, len(nodes))
+	for i, n := range nodes {
+		e, err := t.expr(n)
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = e.at(jsArrow)
+	}
+	return codes, nil
+}
+
+func (t *celTranslator) binary(n *celBinary) (jsExpr, error) {
+	left, err := t.expr(n.left)
+	if err != nil {
+		return jsExpr{}, err
+	}
+	right, err := t.expr(n.right)
+	if err != nil {
+		return jsExpr{}, err
+	}
+	switch n.op {
+	case "in":
+		switch n.right.(type) {
+		case *celList:
+			return jsExpr{right.code + ".includes(" + left.at(jsArrow) + ")", jsMember}, nil
+		case *celMap:
+			return jsExpr{left.at(jsAdditive) + " in " + right.code, jsRelational}, nil
+		}
+		r := right.at(jsMember)
+		return jsExpr{fmt.Sprintf("(Array.isArray(%s) ? %s.includes(%s) : %s in %s)", r, r, left.at(jsArrow), left.at(jsAdditive), r), jsPrimary}, nil
+	case "==", "!=":
+		if isCELNull(n.left) || isCELNull(n.right) {
+			// A missing field is undefined in JavaScript, which equals null only loosely.
+			op := n.op
+			return jsExpr{left.at(jsEquality) + " " + op + " " + right.at(jsRelational), jsEquality}, nil
+		}
+		l, r := valueKind(n.left), valueKind(n.right)
+		switch {
+		case l == celListValue || l == celObjectValue || r == celListValue || r == celObjectValue:
+			return jsExpr{}, fmt.Errorf("%s compares lists and maps by value in CEL, which no JavaScript operator does", n.op)
+		case l == celUnknownValue && r == celUnknownValue:
+			t.note("%s compares lists and maps by value in CEL but by reference in JavaScript; check that the operands are not lists or maps", n.op)
+		}
+	case "+":
+		l, r := valueKind(n.left), valueKind(n.right)
+		if l == celListValue || r == celListValue {
+			return jsExpr{"[..." + left.at(jsArrow) + ", ..." + right.at(jsArrow) + "]", jsPrimary}, nil
+		}
+		if l != celScalarValue && r != celScalarValue {
+			t.note("+ concatenates lists in CEL but converts them to strings in JavaScript; check that the operands are not lists")
+		}
+	case "/":
+		t.note("the division of integers is truncated in CEL; wrap it with Math.trunc() if the operands are integers")
+	}
+	op := jsBinaryOps[n.op]
+	// The operators are left-associative, so the right operand binds tighter.
+	return jsExpr{left.at(op.prec) + " " + op.op + " " + right.at(op.prec+1), op.prec}, nil
+}
+
+func (t *celTranslator) call(n *celCall) (jsExpr, error) {
+	if n.target == nil {
+		return t.function(n)
+	}
+	if m, ok := jsMacros[n.name]; ok && (len(n.args) == 2 || (n.name == "map" && len(n.args) == 3)) {
+		return t.macro(n, m)
+	}
+	if n.name == "exists_one" && len(n.args) == 2 {
+		e, err := t.macro(n, "filter")
+		if err != nil {
+			return jsExpr{}, err
+		}
+		return jsExpr{e.code + ".length === 1", jsEquality}, nil
+	}
+	target, err := t.expr(n.target)
+	if err != nil {
+		return jsExpr{}, err
+	}
+	args, err := t.exprs(n.args)
+	if err != nil {
+		return jsExpr{}, err
+	}
+	switch {
+	case n.name == "size" && len(args) == 0:
+		t.note("size() of a map is Object.keys(...).length in JavaScript")
+		return jsExpr{target.at(jsMember) + ".length", jsMember}, nil
+	case n.name == "matches" && len(args) == 1:
+		return jsExpr{"new RegExp(" + args[0] + ").test(" + target.code + ")", jsMember}, nil
+	}
+	if m, ok := jsMethods[n.name]; ok {
+		return jsExpr{target.at(jsMember) + "." + m + "(" + strings.Join(args, ", ") + ")", jsMember}, nil
+	}
+	return jsExpr{}, fmt.Errorf("function %s() has no JavaScript equivalent", n.name)
+}
+
+// macro translates the comprehension into the JavaScript array method.
+func (t *celTranslator) macro(n *celCall, method string) (jsExpr, error) {
+	v, ok := n.args[0].(*celIdentNode)
+	if !ok {
+		return jsExpr{}, fmt.Errorf("the first argument of %s() must be a variable", n.name)
+	}
+	// A map has no array methods in JavaScript, so a comprehension over a map fails loudly instead of changing its meaning.
+	target, err := t.expr(n.target)
+	if err != nil {
+		return jsExpr{}, err
+	}
+	args, err := t.exprs(n.args[1:])
+	if err != nil {
+		return jsExpr{}, err
+	}
+	code := target.at(jsMember)
+	if len(args) == 2 {
+		// map(x, predicate, transform) keeps the elements satisfying the predicate.
+		code += fmt.Sprintf(".filter((%s) => %s)", v.name, args[0])
+		args = args[1:]
+	}
+	return jsExpr{code + fmt.Sprintf(".%s((%s) => %s)", method, v.name, args[0]), jsMember}, nil
+}
+
+func (t *celTranslator) function(n *celCall) (jsExpr, error) {
+	if n.name == "has" {
+		if len(n.args) != 1 {
+			return jsExpr{}, errors.New("has() takes a field selection")
+		}
+		if _, ok := n.args[0].(*celSelect); !ok {
+			return jsExpr{}, errors.New("has() takes a field selection")
+		}
+		field, err := t.expr(n.args[0])
+		if err != nil {
+			return jsExpr{}, err
+		}
+		return jsExpr{field.at(jsRelational) + " !== undefined", jsEquality}, nil
+	}
+	args, err := t.exprs(n.args)
+	if err != nil {
+		return jsExpr{}, err
+	}
+	switch {
+	case n.name == "size" && len(args) == 1:
+		return t.call(&celCall{target: n.args[0], name: "size"})
+	case (n.name == "int" || n.name == "uint") && len(args) == 1:
+		return jsExpr{"Math.trunc(Number(" + args[0] + "))", jsMember}, nil
+	case n.name == "double" && len(args) == 1:
+		return jsExpr{"Number(" + args[0] + ")", jsMember}, nil
+	case n.name == "string" && len(args) == 1:
+		return jsExpr{"String(" + args[0] + ")", jsMember}, nil
+	case n.name == "dyn" && len(args) == 1:
+		return t.expr(n.args[0])
+	case n.name == "matches" && len(args) == 2:
+		return jsExpr{"new RegExp(" + args[1] + ").test(" + args[0] + ")", jsMember}, nil
+	case n.name == "timestamp" && len(args) == 1:
+		t.note("timestamp() is a Date in JavaScript; convert it with toISOString() where a string is expected")
+		return jsExpr{"new Date(" + args[0] + ")", jsMember}, nil
+	case n.name == "now" && len(args) == 0:
+		t.note("now() is formatted as an RFC 3339 string with milliseconds in JavaScript")
+		return jsExpr{"new Date().toISOString()", jsMember}, nil
+	}
+	return jsExpr{}, fmt.Errorf("function %s() has no JavaScript equivalent", n.name)
+}
+
+// celValueKind is what is known about the value of a CEL expression without its variables.
+type celValueKind int
+
+const (
+	celUnknownValue celValueKind = iota
+	// celScalarValue is a number, a string, a bool or null, which JavaScript compares by value as CEL does.
+	celScalarValue
+	celListValue
+	// celObjectValue is a map or a timestamp, which JavaScript compares by reference.
+	celObjectValue
+)
+
+// celScalarFunctions are the functions and methods returning a scalar.
+var celScalarFunctions = []string{"has", "int", "uint", "double", "string", "matches", "now", "size", "all", "exists", "exists_one"}
+
+func valueKind(n celNode) celValueKind {
+	switch n := n.(type) {
+	case *celLiteral, *celUnary:
+		return celScalarValue
+	case *celIdentNode:
+		if n.name == "true" || n.name == "false" || n.name == "null" {
+			return celScalarValue
+		}
+	case *celBinary:
+		if n.op != "+" {
+			return celScalarValue
+		}
+		l, r := valueKind(n.left), valueKind(n.right)
+		switch {
+		case l == celListValue || r == celListValue:
+			return celListValue
+		case l == celScalarValue || r == celScalarValue:
+			return celScalarValue
+		}
+	case *celConditional:
+		if then := valueKind(n.then); then == valueKind(n.els) {
+			return then
+		}
+	case *celList:
+		return celListValue
+	case *celMap:
+		return celObjectValue
+	case *celCall:
+		switch {
+		case n.target == nil && n.name == "dyn" && len(n.args) == 1:
+			return valueKind(n.args[0])
+		case n.target == nil && n.name == "timestamp":
+			return celObjectValue
+		case n.target != nil && (n.name == "map" || n.name == "filter" || n.name == "split"):
+			return celListValue
+		case slices.Contains(celScalarFunctions, n.name):
+			return celScalarValue
+		}
+		if _, ok := jsMethods[n.name]; ok && n.target != nil {
+			return celScalarValue
+		}
+	}
+	return celUnknownValue
+}
+
+func isCELNull(n celNode) bool {
+	id, ok := n.(*celIdentNode)
+	return ok && id.name == "null"
+}
+
+// jsString returns the JavaScript string literal of s.
+func jsString(s string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,66 @@
+package tailor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTranslateCEL(t *testing.T) {
+	tests := []struct {
+		expr      string
+		want      string
+		wantNotes []string
+		wantErr   bool
+	}{
+		{"context.args.input", "context.args.input", nil, false},
+		{`{"id": context.args.id, "tags": ["a", 'b'], "count": 1.5}`, `({"id": context.args.id, "tags": ["a", "b"], "count": 1.5})`, nil, false},
+		{"{key: 1}", "({[key]: 1})", nil, false},
+		{"null", "null", nil, false},
+		{"a == 1 && c != 'x' || !d", `a === 1 && c !== "x" || !d`, nil, false},
+		{"a == b", "a === b", []string{"== compares lists and maps by value in CEL but by reference in JavaScript; check that the operands are not lists or maps"}, false},
+		{"size(a) != b.lowerAscii()", "a.length !== b.toLowerCase()", []string{"size() of a map is Object.keys(...).length in JavaScript"}, false},
+		{"tags == ['a']", "", nil, true},
+		{"a != {'k': 1}", "", nil, true},
+		{"timestamp(a) == b", "", nil, true},
+		{"a.b == null", "a.b == null", nil, false},
+		{"(a + 1) * c - d % 2", "(a + 1) * c - d % 2", nil, false},
+		{"xs + ys", "xs + ys", []string{"+ concatenates lists in CEL but converts them to strings in JavaScript; check that the operands are not lists"}, false},
+		{"xs.filter(x, x > 0) + ys", "[...xs.filter((x) => x > 0), ...ys]", nil, false},
+		{"name + '!'", `name + "!"`, nil, false},
+		{"a - (b - c)", "a - (b - c)", nil, false},
+		{"-(-a)", "- -a", nil, false},
+		{"a > 0 ? 'pos' : b ? 'x' : 'neg'", `a > 0 ? "pos" : b ? "x" : "neg"`, nil, false},
+		{"x in ['a', 'b']", `["a", "b"].includes(x)`, nil, false},
+		{"x in y", "(Array.isArray(y) ? y.includes(x) : x in y)", nil, false},
+		{"[1] + xs", "[...[1], ...xs]", nil, false},
+		{"items[0].name", "items[0].name", nil, false},
+		{"has(user.attributes.role)", "user.attributes.role !== undefined", nil, false},
+		{"name.startsWith('a') && name.contains(r'\\d')", `name.startsWith("a") && name.includes("\\d")`, nil, false},
+		{"email.matches('^.+@example\\\\.com$')", `new RegExp("^.+@example\\.com$").test(email)`, nil, false},
+		{"int(a) + double(b) + string(c).lowerAscii().size()", "Math.trunc(Number(a)) + Number(b) + String(c).toLowerCase().length", []string{"size() of a map is Object.keys(...).length in JavaScript"}, false},
+		{"items.exists(i, i.qty > 0) && items.all(i, has(i.id))", "items.some((i) => i.qty > 0) && items.every((i) => i.id !== undefined)", nil, false},
+		{"items.map(i, i.qty > 0, i.id)", "items.filter((i) => i.qty > 0).map((i) => i.id)", nil, false},
+		{"a / 2", "a / 2", []string{"the division of integers is truncated in CEL; wrap it with Math.trunc() if the operands are integers"}, false},
+		{"1u + 0x1F + 1e-3", "1 + 0x1F + 1e-3", nil, false},
+		{"now()", "new Date().toISOString()", []string{"now() is formatted as an RFC 3339 string with milliseconds in JavaScript"}, false},
+		{"duration('1h')", "", nil, true},
+		{"a.b(", "", nil, true},
+		{"b'bytes'", "", nil, true},
+		{`"unterminated`, "", nil, true},
+		{" ", "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got := TranslateCEL(tt.expr)
+			if (got.Err != nil) != tt.wantErr {
+				t.Fatalf("TranslateCEL() error = %v, wantErr %v", got.Err, tt.wantErr)
+			}
+			if got.JS != tt.want {
+				t.Errorf("TranslateCEL() = %q, want %q", got.JS, tt.want)
+			}
+			if !reflect.DeepEqual(got.Notes, tt.wantNotes) {
+				t.Errorf("TranslateCEL() notes = %q, want %q", got.Notes, tt.wantNotes)
+			}
+		})
+	}
+}
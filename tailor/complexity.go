@@ -0,0 +1,137 @@
+package tailor
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	tailorv1 "buf.build/gen/go/tailor-inc/tailor/protocolbuffers/go/tailor/v1"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// Weights of the factors of the complexity score.
+const (
+	complexityWeightStep           = 1
+	complexityWeightTestBranch     = 2
+	complexityWeightScript         = 1
+	complexityWeightSelectionDepth = 1
+	complexityWeightType           = 1
+)
+
+var generatedOperationPrefixRe = regexp.MustCompile(`^(create|update|delete|appendDraft|confirmDraft|cancelDraft)`)
+
+// ResolverComplexity is the complexity of a pipeline resolver.
+type ResolverComplexity struct {
+	PipelineNamespaceName string
+	Name                  string
+	Steps                 int
+	// TestBranches is the number of steps with a `test` condition.
+	TestBranches int
+	// Scripts is the number of hooks, scripts and validations of the resolver and its steps.
+	Scripts int
+	// SelectionDepth is the deepest GraphQL selection set of the GraphQL steps.
+	SelectionDepth int
+	// Types is the number of distinct TailorDB types touched by the GraphQL steps.
+	Types int
+}
+
+// Score returns the weighted sum of the complexity factors.
+func (rc *ResolverComplexity) Score() int {
+	return rc.Steps*complexityWeightStep +
+		rc.TestBranches*complexityWeightTestBranch +
+		rc.Scripts*complexityWeightScript +
+		rc.SelectionDepth*complexityWeightSelectionDepth +
+		rc.Types*complexityWeightType
+}
+
+// Complexity computes the complexity of the pipeline resolvers.
+func (c *Client) Complexity(resources *Resources) ([]*ResolverComplexity, error) {
+	typeNames := resources.tailorDBTypeNames()
+	var complexities []*ResolverComplexity
+	for _, p := range resources.Pipelines {
+		for _, r := range p.Resolvers {
+			rc, err := resolverComplexity(p.NamespaceName, r, typeNames)
+			if err != nil {
+				return nil, err
+			}
+			complexities = append(complexities, rc)
+		}
+	}
+
+	return complexities, nil
+}
+
+// MostComplex returns the n resolvers with the highest complexity score.
+func MostComplex(complexities []*ResolverComplexity, n int) []*ResolverComplexity {
+	sorted := slices.Clone(complexities)
+	slices.SortStableFunc(sorted, func(a, b *ResolverComplexity) int {
+		return cmp.Compare(b.Score(), a.Score())
+	})
+	if n >= 0 && len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+func resolverComplexity(namespace string, r *PipelineResolver, typeNames []string) (*ResolverComplexity, error) {
+	rc := &ResolverComplexity{
+		PipelineNamespaceName: namespace,
+		Name:                  r.Name,
+		Steps:                 len(r.Steps),
+		Scripts:               countNonEmpty(r.PreHook, r.PreScript, r.PostScript, r.PostHook),
+	}
+	touched := map[string]struct{}{}
+	for _, s := range r.Steps {
+		if s.Operation.Test != "" {
+			rc.TestBranches++
+		}
+		rc.Scripts += countNonEmpty(s.PreValidation, s.PreScript, s.PreHook, s.PostScript, s.PostValidation, s.PostHook)
+		if s.Operation.Type != tailorv1.PipelineResolver_OPERATION_TYPE_GRAPHQL {
+			continue
+		}
+		query, err := parser.ParseQuery(&ast.Source{
+			Input: s.Operation.Source,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse GraphQL operation in %s/%s step %s: %w", namespace, r.Name, s.Name, err)
+		}
+		for _, op := range query.Operations {
+			rc.SelectionDepth = max(rc.SelectionDepth, analyzeSelectionSet(op.SelectionSet, query.Fragments, defaultListSize, map[string]bool{}).depth)
+			for _, selection := range op.SelectionSet {
+				f, ok := selection.(*ast.Field)
+				if !ok {
+					continue
+				}
+				if t := touchedType(f.Name, typeNames); t != "" {
+					touched[t] = struct{}{}
+				}
+			}
+		}
+	}
+	rc.Types = len(touched)
+	return rc, nil
+}
+
+// touchedType returns the TailorDB type whose generated query or mutation is the field, or an empty string.
+func touchedType(field string, typeNames []string) string {
+	name := generatedOperationPrefixRe.ReplaceAllString(field, "")
+	for _, t := range typeNames {
+		if strings.EqualFold(name, t) || strings.EqualFold(name, t+"s") {
+			return t
+		}
+	}
+	return ""
+}
+
+func countNonEmpty(values ...string) int {
+	var count int
+	for _, v := range values {
+		if v != "" {
+			count++
+		}
+	}
+	return count
+}
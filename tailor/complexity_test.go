@@ -0,0 +1,211 @@
+package tailor
+
+import (
+	"reflect"
+	"testing"
+
+	tailorv1 "buf.build/gen/go/tailor-inc/tailor/protocolbuffers/go/tailor/v1"
+)
+
+func TestClient_Complexity(t *testing.T) {
+	resources := &Resources{
+		Pipelines: []*Pipeline{
+			{
+				NamespaceName: "test-namespace",
+				Resolvers: []*PipelineResolver{
+					{
+						Name:    "complexResolver",
+						PreHook: "({ input: context.args })",
+						Steps: []*PipelineStep{
+							{
+								Name: "getUser",
+								Operation: PipelineStepOperation{
+									Type:   tailorv1.PipelineResolver_OPERATION_TYPE_GRAPHQL,
+									Source: "query { users { edges { node { id ...PostFields } } } } fragment PostFields on User { posts { id } }",
+								},
+							},
+							{
+								Name:     "createOrder",
+								PostHook: "args.createOrder",
+								Operation: PipelineStepOperation{
+									Type:   tailorv1.PipelineResolver_OPERATION_TYPE_GRAPHQL,
+									Source: "mutation { createOrder(input: {}) { id } updateUser(id: \"1\", input: {}) { id } }",
+									Test:   "args.users != null",
+								},
+							},
+							{
+								Name: "notify",
+								Operation: PipelineStepOperation{
+									Type: tailorv1.PipelineResolver_OPERATION_TYPE_FUNCTION,
+									Test: "args.createOrder != null",
+								},
+							},
+						},
+					},
+					{
+						Name: "simpleResolver",
+						Steps: []*PipelineStep{
+							{Name: "step1"},
+						},
+					},
+				},
+			},
+		},
+		TailorDBs: []*TailorDB{
+			{
+				NamespaceName: "test-db",
+				Types: []*TailorDBType{
+					{Name: "User"},
+					{Name: "Order"},
+				},
+			},
+		},
+	}
+
+	c := &Client{}
+	got, err := c.Complexity(resources)
+	if err != nil {
+		t.Fatalf("Client.Complexity() error = %v", err)
+	}
+	want := []*ResolverComplexity{
+		{
+			PipelineNamespaceName: "test-namespace",
+			Name:                  "complexResolver",
+			Steps:                 3,
+			TestBranches:          2,
+			Scripts:               2,
+			SelectionDepth:        5,
+			Types:                 2,
+		},
+		{
+			PipelineNamespaceName: "test-namespace",
+			Name:                  "simpleResolver",
+			Steps:                 1,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		for i := range got {
+			t.Logf("got[%d] = %+v", i, got[i])
+		}
+		t.Fatalf("Client.Complexity() mismatch")
+	}
+	if score := got[0].Score(); score != 16 {
+		t.Errorf("Score() = %d, want 16", score)
+	}
+
+	most := MostComplex(got, 1)
+	if len(most) != 1 || most[0].Name != "complexResolver" {
+		t.Errorf("MostComplex() = %v, want [complexResolver]", most)
+	}
+}
+
+func TestClient_Complexity_FilteredTypes(t *testing.T) {
+	resources := &Resources{
+		Pipelines: []*Pipeline{
+			{
+				NamespaceName: "test-namespace",
+				Resolvers: []*PipelineResolver{
+					{
+						Name: "createOrder",
+						Steps: []*PipelineStep{
+							{
+								Name: "createOrder",
+								Operation: PipelineStepOperation{
+									Type:   tailorv1.PipelineResolver_OPERATION_TYPE_GRAPHQL,
+									Source: "mutation { createOrder(input: {}) { id } }",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		// The type is used by the resolver even if its namespace is filtered out.
+		filteredTypeNames: []string{"Order"},
+	}
+
+	c := &Client{}
+	got, err := c.Complexity(resources)
+	if err != nil {
+		t.Fatalf("Client.Complexity() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Types != 1 {
+		t.Errorf("Client.Complexity() = %+v, want 1 type", got)
+	}
+}
+
+func TestClient_Complexity_InvalidGraphQL(t *testing.T) {
+	resources := &Resources{
+		Pipelines: []*Pipeline{
+			{
+				NamespaceName: "test-namespace",
+				Resolvers: []*PipelineResolver{
+					{
+						Name: "brokenResolver",
+						Steps: []*PipelineStep{
+							{
+								Name: "step1",
+								Operation: PipelineStepOperation{
+									Type:   tailorv1.PipelineResolver_OPERATION_TYPE_GRAPHQL,
+									Source: "query { users {",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	c := &Client{}
+	if _, err := c.Complexity(resources); err == nil {
+		t.Error("Client.Complexity() expected error for invalid GraphQL")
+	}
+}
+
+func TestClient_Lint_Complexity(t *testing.T) {
+	cfg := createTestConfig(t)
+	cfg.Lint.Rules.Pipeline.Complexity.Enabled = true
+	cfg.Lint.Rules.Pipeline.Complexity.Max = 3
+
+	resources := &Resources{
+		Pipelines: []*Pipeline{
+			{
+				NamespaceName: "test-ns",
+				Resolvers: []*PipelineResolver{
+					{
+						Name: "complexResolver",
+						Steps: []*PipelineStep{
+							{Name: "step1"},
+							{Name: "step2", Operation: PipelineStepOperation{Test: "args.step1 != null"}},
+						},
+					},
+					{
+						Name: "simpleResolver",
+						Steps: []*PipelineStep{
+							{Name: "step1"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	client, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	warns, err := client.Lint(resources)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(warns) != 1 {
+		t.Fatalf("Expected 1 warning, got %d", len(warns))
+	}
+	if warns[0].Rule != LintRulePipelineComplexity {
+		t.Errorf("Expected warning rule %s, got %s", LintRulePipelineComplexity, warns[0].Rule)
+	}
+	if want := "resolver is too complex (complexity 4 > 3)"; warns[0].Message != want {
+		t.Errorf("Expected warning message %q, got %q", want, warns[0].Message)
+	}
+}
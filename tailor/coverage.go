@@ -2,6 +2,11 @@ package tailor
 
 import (
 	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	tailorv1 "buf.build/gen/go/tailor-inc/tailor/protocolbuffers/go/tailor/v1"
 )
 
 type ResolverCoverage struct {
@@ -9,15 +14,92 @@ type ResolverCoverage struct {
 	Name                  string
 	TotalSteps            int
 	CoveredSteps          int
+	SucceededExecutions   int
+	FailedExecutions      int
 	Steps                 []*StepCoverage
 }
 
 type StepCoverage struct {
-	Name  string
-	Count int
+	Name           string
+	Count          int
+	SucceededCount int
+	FailedCount    int
+	Errors         []*StepError
+}
+
+// StepError is an error message of the executions that failed at the step.
+type StepError struct {
+	Message string
+	Count   int
+}
+
+type CoverageViolation struct {
+	// Scope is "workspace", "<namespace>" or "<namespace>/<resolver>".
+	Scope     string
+	Minimum   float64
+	Coverage  float64
+	Resolvers []*ResolverCoverage
+	// NotFound is true when the threshold matches no resolver, e.g., because of a typo in the namespace or resolver name.
+	NotFound bool
+}
+
+// Percentage returns the step coverage of the resolver in percent.
+func (rc *ResolverCoverage) Percentage() float64 {
+	return coveragePercentage(rc.CoveredSteps, rc.TotalSteps)
+}
+
+// UncoveredSteps returns the names of the steps that have never been executed.
+func (rc *ResolverCoverage) UncoveredSteps() []string {
+	var names []string
+	for _, s := range rc.Steps {
+		if s.Count == 0 {
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
+
+// FailedSteps returns the steps where at least one execution failed.
+func (rc *ResolverCoverage) FailedSteps() []*StepCoverage {
+	var steps []*StepCoverage
+	for _, s := range rc.Steps {
+		if s.FailedCount > 0 {
+			steps = append(steps, s)
+		}
+	}
+	return steps
+}
+
+// UnsucceededSteps returns the names of the steps that have never been executed on the success path.
+func (rc *ResolverCoverage) UnsucceededSteps() []string {
+	var names []string
+	for _, s := range rc.Steps {
+		if s.SucceededCount == 0 {
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
+
+func (sc *StepCoverage) addError(message string) {
+	for _, e := range sc.Errors {
+		if e.Message == message {
+			e.Count++
+			return
+		}
+	}
+	sc.Errors = append(sc.Errors, &StepError{
+		Message: message,
+		Count:   1,
+	})
 }
 
 func (c *Client) Coverage(resources *Resources) ([]*ResolverCoverage, error) {
+	return coverage(resources)
+}
+
+// coverage counts the executions of the steps of the resolvers from their execution results.
+func coverage(resources *Resources) ([]*ResolverCoverage, error) {
 	var coverages []*ResolverCoverage
 	for _, p := range resources.Pipelines {
 		for _, r := range p.Resolvers {
@@ -40,31 +122,52 @@ func (c *Client) Coverage(resources *Resources) ([]*ResolverCoverage, error) {
 				continue
 			}
 			for _, result := range r.ExecutionResults {
-				if result.Context == nil {
-					// no branch steps
+				failed := executionFailed(result)
+				if failed {
+					rc.FailedExecutions++
+				} else {
+					rc.SucceededExecutions++
+				}
+				executed := make([]bool, len(stepNames))
+				steps, ok := result.GetContext().GetFields()["pipeline"]
+				if !ok {
+					// No step results in the context (e.g., no branch steps, or the execution failed before recording them):
+					// the steps up to the last executed one are counted as executed.
 					for i, stepName := range stepNames {
-						rc.Steps[i].Count++
+						executed[i] = true
 						if result.LastPipelineName == stepName {
 							break
 						}
 					}
-					continue
-				}
-				steps, ok := result.Context.Fields["pipeline"]
-				if !ok {
-					continue
-				}
-				b, err := json.Marshal(steps)
-				if err != nil {
-					return nil, err
-				}
-				var m map[string]any
-				if err := json.Unmarshal(b, &m); err != nil {
-					return nil, err
+				} else {
+					b, err := json.Marshal(steps)
+					if err != nil {
+						return nil, err
+					}
+					var m map[string]any
+					if err := json.Unmarshal(b, &m); err != nil {
+						return nil, err
+					}
+					for i, stepName := range stepNames {
+						if _, ok := m[stepName]; ok {
+							executed[i] = true
+						}
+					}
 				}
-				for i, stepName := range stepNames {
-					if _, ok := m[stepName]; ok {
-						rc.Steps[i].Count++
+				for i, s := range rc.Steps {
+					if s.Name == result.LastPipelineName && failed {
+						// The step where the execution failed is counted as executed even if its result is missing from the context.
+						s.Count++
+						s.FailedCount++
+						s.addError(result.GetErrorMessage())
+						continue
+					}
+					if !executed[i] {
+						continue
+					}
+					s.Count++
+					if !failed {
+						s.SucceededCount++
 					}
 				}
 			}
@@ -79,3 +182,118 @@ func (c *Client) Coverage(resources *Resources) ([]*ResolverCoverage, error) {
 
 	return coverages, nil
 }
+
+// CheckCoverage checks the coverages against the minimums in the config and returns the thresholds that are not met.
+// A namespace or resolver threshold matching no resolver is a violation too, so that a typo does not pass the check forever.
+// The thresholds of the namespaces and resolvers excluded by the filter are skipped.
+func (c *Client) CheckCoverage(coverages []*ResolverCoverage) []*CoverageViolation {
+	var violations []*CoverageViolation
+
+	// Workspace
+	if c.cfg.Coverage.Minimum > 0 {
+		if v := checkCoverage("workspace", c.cfg.Coverage.Minimum, coverages); v != nil {
+			violations = append(violations, v)
+		}
+	}
+
+	// Namespaces
+	namespaces := make([]string, 0, len(c.cfg.Coverage.Namespaces))
+	for ns := range c.cfg.Coverage.Namespaces {
+		namespaces = append(namespaces, ns)
+	}
+	slices.Sort(namespaces)
+	for _, ns := range namespaces {
+		if !c.filter.namespace(ns) {
+			continue
+		}
+		var scoped []*ResolverCoverage
+		for _, rc := range coverages {
+			if rc.PipelineNamespaceName == ns {
+				scoped = append(scoped, rc)
+			}
+		}
+		if len(scoped) == 0 {
+			violations = append(violations, &CoverageViolation{Scope: ns, Minimum: c.cfg.Coverage.Namespaces[ns], NotFound: true})
+			continue
+		}
+		if v := checkCoverage(ns, c.cfg.Coverage.Namespaces[ns], scoped); v != nil {
+			violations = append(violations, v)
+		}
+	}
+
+	// Resolvers
+	resolvers := make([]string, 0, len(c.cfg.Coverage.Resolvers))
+	for name := range c.cfg.Coverage.Resolvers {
+		resolvers = append(resolvers, name)
+	}
+	slices.Sort(resolvers)
+	for _, name := range resolvers {
+		if ns, r, ok := strings.Cut(name, "/"); ok && (!c.filter.namespace(ns) || !c.filter.resolver(ns, r)) {
+			continue
+		}
+		var scoped []*ResolverCoverage
+		for _, rc := range coverages {
+			if fmt.Sprintf("%s/%s", rc.PipelineNamespaceName, rc.Name) == name {
+				scoped = append(scoped, rc)
+			}
+		}
+		if len(scoped) == 0 {
+			violations = append(violations, &CoverageViolation{Scope: name, Minimum: c.cfg.Coverage.Resolvers[name], NotFound: true})
+			continue
+		}
+		if v := checkCoverage(name, c.cfg.Coverage.Resolvers[name], scoped); v != nil {
+			violations = append(violations, v)
+		}
+	}
+
+	return violations
+}
+
+// checkCoverage returns a violation if the total coverage of the resolvers is below the minimum.
+func checkCoverage(scope string, minimum float64, coverages []*ResolverCoverage) *CoverageViolation {
+	if len(coverages) == 0 {
+		return nil
+	}
+	var total, covered int
+	for _, rc := range coverages {
+		total += rc.TotalSteps
+		covered += rc.CoveredSteps
+	}
+	if total == 0 {
+		return nil
+	}
+	cover := coveragePercentage(covered, total)
+	if cover >= minimum {
+		return nil
+	}
+	v := &CoverageViolation{
+		Scope:    scope,
+		Minimum:  minimum,
+		Coverage: cover,
+	}
+	for _, rc := range coverages {
+		if rc.CoveredSteps < rc.TotalSteps {
+			v.Resolvers = append(v.Resolvers, rc)
+		}
+	}
+	return v
+}
+
+func coveragePercentage(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(covered) / float64(total) * 100
+}
+
+// executionFailed reports whether the execution did not complete successfully.
+// Results without a status are treated as successful.
+func executionFailed(result *tailorv1.PipelineResolverExecutionResult) bool {
+	switch result.GetStatus() {
+	case tailorv1.PipelineResolverExecutionResultStatus_PIPELINE_RESOLVER_EXECUTION_RESULT_STATUS_UNSPECIFIED,
+		tailorv1.PipelineResolverExecutionResultStatus_PIPELINE_RESOLVER_EXECUTION_RESULT_STATUS_SUCCESS:
+		return false
+	default:
+		return true
+	}
+}
@@ -6,6 +6,8 @@ import (
 
 	tailorv1 "buf.build/gen/go/tailor-inc/tailor/protocolbuffers/go/tailor/v1"
 	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/tailor-platform/patterner/config"
 )
 
 func TestClient_Coverage(t *testing.T) {
@@ -134,10 +136,11 @@ func TestClient_Coverage_ExecutionResultsWithContext(t *testing.T) {
 			Name:                  "test-resolver",
 			TotalSteps:            3,
 			CoveredSteps:          2,
+			SucceededExecutions:   1,
 			Steps: []*StepCoverage{
-				{Name: "step1", Count: 1},
+				{Name: "step1", Count: 1, SucceededCount: 1},
 				{Name: "step2", Count: 0},
-				{Name: "step3", Count: 1},
+				{Name: "step3", Count: 1, SucceededCount: 1},
 			},
 		},
 	}
@@ -185,9 +188,87 @@ func TestClient_Coverage_ExecutionResultsWithoutContext(t *testing.T) {
 			Name:                  "test-resolver",
 			TotalSteps:            3,
 			CoveredSteps:          2,
+			SucceededExecutions:   1,
 			Steps: []*StepCoverage{
-				{Name: "step1", Count: 1},
-				{Name: "step2", Count: 1},
+				{Name: "step1", Count: 1, SucceededCount: 1},
+				{Name: "step2", Count: 1, SucceededCount: 1},
+				{Name: "step3", Count: 0},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Client.Coverage() = %v, want %v", got, expected)
+	}
+}
+
+func TestClient_Coverage_FailedExecutions(t *testing.T) {
+	failure := tailorv1.PipelineResolverExecutionResultStatus_PIPELINE_RESOLVER_EXECUTION_RESULT_STATUS_FAILURE
+	success := tailorv1.PipelineResolverExecutionResultStatus_PIPELINE_RESOLVER_EXECUTION_RESULT_STATUS_SUCCESS
+	resources := &Resources{
+		Pipelines: []*Pipeline{
+			{
+				NamespaceName: "test-namespace",
+				Resolvers: []*PipelineResolver{
+					{
+						Name: "test-resolver",
+						Steps: []*PipelineStep{
+							{Name: "step1"},
+							{Name: "step2"},
+							{Name: "step3"},
+						},
+						ExecutionResults: []*tailorv1.PipelineResolverExecutionResult{
+							{
+								Status:           success,
+								LastPipelineName: "step1",
+							},
+							{
+								Status:           failure,
+								LastPipelineName: "step2",
+								ErrorMessage:     "permission denied",
+							},
+							{
+								Status:           failure,
+								LastPipelineName: "step2",
+								ErrorMessage:     "permission denied",
+							},
+							{
+								// The context has no step results when the execution failed before recording them.
+								Status:           failure,
+								LastPipelineName: "step2",
+								ErrorMessage:     "not found",
+								Context: &structpb.Struct{Fields: map[string]*structpb.Value{
+									"args": structpb.NewStringValue("{}"),
+								}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	c := &Client{}
+	got, err := c.Coverage(resources)
+	if err != nil {
+		t.Errorf("Client.Coverage() error = %v, wantErr false", err)
+		return
+	}
+
+	expected := []*ResolverCoverage{
+		{
+			PipelineNamespaceName: "test-namespace",
+			Name:                  "test-resolver",
+			TotalSteps:            3,
+			CoveredSteps:          2,
+			SucceededExecutions:   1,
+			FailedExecutions:      3,
+			Steps: []*StepCoverage{
+				{Name: "step1", Count: 4, SucceededCount: 1},
+				{Name: "step2", Count: 3, FailedCount: 3, Errors: []*StepError{
+					{Message: "permission denied", Count: 2},
+					{Message: "not found", Count: 1},
+				}},
 				{Name: "step3", Count: 0},
 			},
 		},
@@ -196,6 +277,12 @@ func TestClient_Coverage_ExecutionResultsWithoutContext(t *testing.T) {
 	if !reflect.DeepEqual(got, expected) {
 		t.Errorf("Client.Coverage() = %v, want %v", got, expected)
 	}
+	if got := got[0].UnsucceededSteps(); !reflect.DeepEqual(got, []string{"step2", "step3"}) {
+		t.Errorf("ResolverCoverage.UnsucceededSteps() = %v, want [step2 step3]", got)
+	}
+	if got := got[0].FailedSteps(); len(got) != 1 || got[0].Name != "step2" {
+		t.Errorf("ResolverCoverage.FailedSteps() = %v, want [step2]", got)
+	}
 }
 
 func TestResolverCoverage_Struct(t *testing.T) {
@@ -302,3 +389,113 @@ func TestClient_Coverage_EdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestClient_CheckCoverage(t *testing.T) {
+	coverages := []*ResolverCoverage{
+		{
+			PipelineNamespaceName: "ns1",
+			Name:                  "resolver1",
+			TotalSteps:            2,
+			CoveredSteps:          2,
+			Steps: []*StepCoverage{
+				{Name: "step1", Count: 1},
+				{Name: "step2", Count: 1},
+			},
+		},
+		{
+			PipelineNamespaceName: "ns2",
+			Name:                  "resolver2",
+			TotalSteps:            2,
+			CoveredSteps:          1,
+			Steps: []*StepCoverage{
+				{Name: "step1", Count: 3},
+				{Name: "step2", Count: 0},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		coverage config.Coverage
+		filter   config.Filter
+		want     []string
+	}{
+		{
+			name:     "no thresholds",
+			coverage: config.Coverage{},
+			want:     nil,
+		},
+		{
+			name:     "workspace minimum met",
+			coverage: config.Coverage{Minimum: 75},
+			want:     nil,
+		},
+		{
+			name:     "workspace minimum not met",
+			coverage: config.Coverage{Minimum: 80},
+			want:     []string{"workspace"},
+		},
+		{
+			name: "namespace and resolver minimums",
+			coverage: config.Coverage{
+				Namespaces: map[string]float64{"ns1": 100, "ns2": 60},
+				Resolvers:  map[string]float64{"ns2/resolver2": 50, "ns1/resolver1": 100},
+			},
+			want: []string{"ns2"},
+		},
+		{
+			name: "unknown namespace and resolver are violations",
+			coverage: config.Coverage{
+				Namespaces: map[string]float64{"unknown": 100},
+				Resolvers:  map[string]float64{"ns1/unknown": 50, "resolver1": 50},
+			},
+			want: []string{"unknown", "ns1/unknown", "resolver1"},
+		},
+		{
+			name: "filtered namespace and resolver are skipped",
+			coverage: config.Coverage{
+				Namespaces: map[string]float64{"other": 100},
+				Resolvers:  map[string]float64{"other/resolver": 100, "ns1/debug": 100},
+			},
+			filter: config.Filter{
+				Namespaces: config.Patterns{Include: []string{"ns*"}},
+				Resolvers:  config.Patterns{Exclude: []string{"debug*"}},
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfig(t)
+			cfg.Coverage = tt.coverage
+			filter, err := newResourceFilter(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := &Client{cfg: cfg, filter: filter}
+			violations := c.CheckCoverage(coverages)
+			var got []string
+			for _, v := range violations {
+				got = append(got, v.Scope)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Client.CheckCoverage() scopes = %v, want %v", got, tt.want)
+			}
+			for _, v := range violations {
+				if v.NotFound {
+					if len(v.Resolvers) != 0 {
+						t.Errorf("Client.CheckCoverage() offending resolvers of %s = %v, want none", v.Scope, v.Resolvers)
+					}
+					continue
+				}
+				if len(v.Resolvers) != 1 || v.Resolvers[0].Name != "resolver2" {
+					t.Errorf("Client.CheckCoverage() offending resolvers = %v, want [resolver2]", v.Resolvers)
+				}
+				if got := v.Resolvers[0].UncoveredSteps(); !reflect.DeepEqual(got, []string{"step2"}) {
+					t.Errorf("ResolverCoverage.UncoveredSteps() = %v, want [step2]", got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package tailor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	tailorv1 "buf.build/gen/go/tailor-inc/tailor/protocolbuffers/go/tailor/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	cursorFileName = "execution_results.json"
+	// cursorVersion is the version of the file. Version 1 stored the whole context of the results.
+	cursorVersion = 2
+	// executionResultsOverlap is how far before the newest stored result the results are refetched,
+	// so that the results still in progress on the previous run are updated.
+	executionResultsOverlap = 5 * time.Minute
+)
+
+// executionResultStore persists the execution results of the resolvers of a workspace
+// so that only the results newer than the stored ones are fetched on subsequent runs.
+type executionResultStore struct {
+	path    string
+	refresh bool
+
+	mu      sync.Mutex
+	cursors map[string]*executionResultCursor
+}
+
+type cursorFile struct {
+	Version int                               `json:"version"`
+	Cursors map[string]*executionResultCursor `json:"cursors"`
+}
+
+// executionResultCursor is the stored execution results of a resolver.
+type executionResultCursor struct {
+	View tailorv1.PipelineResolverExecutionResultView `json:"view"`
+	// Since is the time from which Results are complete.
+	Since time.Time `json:"since"`
+	// NewestCreatedAt and NewestID identify the newest stored result.
+	NewestCreatedAt time.Time `json:"newest_created_at"`
+	NewestID        string    `json:"newest_id"`
+	// Results are the serialized results ordered by CreatedAt descending, stripped by storedExecutionResult.
+	Results [][]byte `json:"results"`
+}
+
+// loadExecutionResultStore loads the stored execution results of the workspace from the directory.
+// A missing or unreadable file is treated as empty.
+func loadExecutionResultStore(dir, workspaceID string, refresh bool) *executionResultStore {
+	s := &executionResultStore{
+		path:    filepath.Join(dir, workspaceID, cursorFileName),
+		refresh: refresh,
+		cursors: map[string]*executionResultCursor{},
+	}
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return s
+	}
+	var f cursorFile
+	if err := json.Unmarshal(b, &f); err != nil || f.Version != cursorVersion {
+		return s
+	}
+	for key, cur := range f.Cursors {
+		if cur != nil {
+			s.cursors[key] = cur
+		}
+	}
+	return s
+}
+
+// get returns the cursor of the resolver if the stored results are complete since the time and fetched with the view.
+func (s *executionResultStore) get(key string, view tailorv1.PipelineResolverExecutionResultView, since time.Time) (*executionResultCursor, bool) {
+	if s == nil || s.refresh {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cur, ok := s.cursors[key]
+	if !ok || cur.View != view || cur.Since.After(since) {
+		return nil, false
+	}
+	return cur, true
+}
+
+func (s *executionResultStore) put(key string, cur *executionResultCursor) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursors[key] = cur
+}
+
+// save writes the stored execution results to the file.
+func (s *executionResultStore) save() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return writeJSONFile(s.path, &cursorFile{
+		Version: cursorVersion,
+		Cursors: s.cursors,
+	})
+}
+
+// fetchFrom returns the time until which the results must be fetched, going back from the newest.
+func (cur *executionResultCursor) fetchFrom(since time.Time) time.Time {
+	from := cur.NewestCreatedAt.Add(-executionResultsOverlap)
+	if from.Before(since) {
+		return since
+	}
+	return from
+}
+
+// storedExecutionResult returns the execution result to store, keeping only the fields the analyses read.
+// The context holds the resolver arguments and the step outputs of the production workspace,
+// so only the names of the executed steps are kept from it, with their outputs stripped.
+func storedExecutionResult(er *tailorv1.PipelineResolverExecutionResult) *tailorv1.PipelineResolverExecutionResult {
+	stored := &tailorv1.PipelineResolverExecutionResult{
+		Id:               er.GetId(),
+		NamespaceName:    er.GetNamespaceName(),
+		ResolverName:     er.GetResolverName(),
+		Status:           er.GetStatus(),
+		LastPipelineName: er.GetLastPipelineName(),
+		ErrorMessage:     er.GetErrorMessage(),
+		CreatedAt:        er.GetCreatedAt(),
+		UpdatedAt:        er.GetUpdatedAt(),
+	}
+	steps := er.GetContext().GetFields()["pipeline"].GetStructValue()
+	if steps == nil {
+		return stored
+	}
+	executed := &structpb.Struct{Fields: map[string]*structpb.Value{}}
+	for name := range steps.GetFields() {
+		executed.Fields[name] = structpb.NewNullValue()
+	}
+	stored.Context = &structpb.Struct{Fields: map[string]*structpb.Value{
+		"pipeline": structpb.NewStructValue(executed),
+	}}
+	return stored
+}
+
+// mergeExecutionResults merges the fetched results into the stored ones, dropping the results created before since.
+// Fetched results replace the stored results with the same ID. The merged results are ordered by CreatedAt descending.
+func mergeExecutionResults(fetched, stored []*tailorv1.PipelineResolverExecutionResult, since time.Time) []*tailorv1.PipelineResolverExecutionResult {
+	merged := make([]*tailorv1.PipelineResolverExecutionResult, 0, len(fetched)+len(stored))
+	ids := map[string]struct{}{}
+	for _, r := range fetched {
+		ids[r.GetId()] = struct{}{}
+		merged = append(merged, r)
+	}
+	for _, r := range stored {
+		if _, ok := ids[r.GetId()]; ok {
+			continue
+		}
+		if r.GetCreatedAt().AsTime().Before(since) {
+			continue
+		}
+		merged = append(merged, r)
+	}
+	slices.SortStableFunc(merged, func(a, b *tailorv1.PipelineResolverExecutionResult) int {
+		return b.GetCreatedAt().AsTime().Compare(a.GetCreatedAt().AsTime())
+	})
+	return merged
+}
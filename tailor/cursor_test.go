@@ -0,0 +1,205 @@
+package tailor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tailorv1 "buf.build/gen/go/tailor-inc/tailor/protocolbuffers/go/tailor/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestExecutionResultStore(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	basic := tailorv1.PipelineResolverExecutionResultView_PIPELINE_RESOLVER_EXECUTION_RESULT_VIEW_BASIC
+	full := tailorv1.PipelineResolverExecutionResultView_PIPELINE_RESOLVER_EXECUTION_RESULT_VIEW_FULL
+
+	s := loadExecutionResultStore(dir, "test-workspace-id", false)
+	s.put("ns/resolver", &executionResultCursor{
+		View:            basic,
+		Since:           base,
+		NewestCreatedAt: base.Add(time.Hour),
+		NewestID:        "r2",
+		Results:         [][]byte{[]byte("r2"), []byte("r1")},
+	})
+	if err := s.save(); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		refresh bool
+		key     string
+		view    tailorv1.PipelineResolverExecutionResultView
+		since   time.Time
+		wantHit bool
+	}{
+		{
+			name:    "same window",
+			key:     "ns/resolver",
+			view:    basic,
+			since:   base,
+			wantHit: true,
+		},
+		{
+			name:    "shorter window",
+			key:     "ns/resolver",
+			view:    basic,
+			since:   base.Add(30 * time.Minute),
+			wantHit: true,
+		},
+		{
+			name:  "longer window",
+			key:   "ns/resolver",
+			view:  basic,
+			since: base.Add(-time.Minute),
+		},
+		{
+			name:  "different view",
+			key:   "ns/resolver",
+			view:  full,
+			since: base,
+		},
+		{
+			name:  "unknown resolver",
+			key:   "ns/other",
+			view:  basic,
+			since: base,
+		},
+		{
+			name:    "refresh",
+			refresh: true,
+			key:     "ns/resolver",
+			view:    basic,
+			since:   base,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loaded := loadExecutionResultStore(dir, "test-workspace-id", tt.refresh)
+			cur, ok := loaded.get(tt.key, tt.view, tt.since)
+			if ok != tt.wantHit {
+				t.Fatalf("get() hit = %v, want %v", ok, tt.wantHit)
+			}
+			if ok && (cur.NewestID != "r2" || len(cur.Results) != 2) {
+				t.Errorf("get() = %+v, want the stored cursor", cur)
+			}
+		})
+	}
+}
+
+func TestExecutionResultCursor_fetchFrom(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		newest time.Time
+		since  time.Time
+		want   time.Time
+	}{
+		{
+			name:   "refetch the overlap before the newest result",
+			newest: base.Add(time.Hour),
+			since:  base,
+			want:   base.Add(time.Hour - executionResultsOverlap),
+		},
+		{
+			name:   "no earlier than since",
+			newest: base.Add(time.Minute),
+			since:  base,
+			want:   base,
+		},
+		{
+			name:  "no stored results",
+			since: base,
+			want:  base,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cur := &executionResultCursor{NewestCreatedAt: tt.newest}
+			if got := cur.fetchFrom(tt.since); !got.Equal(tt.want) {
+				t.Errorf("fetchFrom() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeExecutionResults(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	result := func(id string, minutes int, status tailorv1.PipelineResolverExecutionResultStatus) *tailorv1.PipelineResolverExecutionResult {
+		return &tailorv1.PipelineResolverExecutionResult{
+			Id:        id,
+			Status:    status,
+			CreatedAt: timestamppb.New(base.Add(time.Duration(minutes) * time.Minute)),
+		}
+	}
+	success := tailorv1.PipelineResolverExecutionResultStatus_PIPELINE_RESOLVER_EXECUTION_RESULT_STATUS_SUCCESS
+	unspecified := tailorv1.PipelineResolverExecutionResultStatus_PIPELINE_RESOLVER_EXECUTION_RESULT_STATUS_UNSPECIFIED
+
+	fetched := []*tailorv1.PipelineResolverExecutionResult{
+		result("r4", 40, success),
+		result("r3", 30, success),
+	}
+	stored := []*tailorv1.PipelineResolverExecutionResult{
+		result("r3", 30, unspecified),
+		result("r2", 20, success),
+		result("r1", 10, success),
+	}
+	got := mergeExecutionResults(fetched, stored, base.Add(15*time.Minute))
+	want := []*tailorv1.PipelineResolverExecutionResult{
+		fetched[0],
+		fetched[1],
+		stored[1],
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeExecutionResults() = %v, want %v", got, want)
+	}
+}
+
+func TestStoredExecutionResult(t *testing.T) {
+	created := timestamppb.New(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	updated := timestamppb.New(time.Date(2025, 1, 1, 0, 0, 1, 0, time.UTC))
+	ctx, err := structpb.NewStruct(map[string]any{
+		"args": map[string]any{"email": "user@example.com"},
+		"pipeline": map[string]any{
+			"getUser":     map[string]any{"name": "User"},
+			"createOrder": nil,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	er := &tailorv1.PipelineResolverExecutionResult{
+		Id:               "r1",
+		NamespaceName:    "ns",
+		ResolverName:     "resolver",
+		Status:           tailorv1.PipelineResolverExecutionResultStatus_PIPELINE_RESOLVER_EXECUTION_RESULT_STATUS_FAILURE,
+		Context:          ctx,
+		LastPipelineName: "createOrder",
+		ErrorMessage:     "failed",
+		CreatedAt:        created,
+		UpdatedAt:        updated,
+	}
+
+	got := storedExecutionResult(er)
+	if got.GetId() != "r1" || got.GetNamespaceName() != "ns" || got.GetResolverName() != "resolver" || got.GetStatus() != er.GetStatus() ||
+		got.GetLastPipelineName() != "createOrder" || got.GetErrorMessage() != "failed" || got.GetCreatedAt() != created || got.GetUpdatedAt() != updated {
+		t.Errorf("storedExecutionResult() = %v, want the fields other than the context kept", got)
+	}
+	want := map[string]any{
+		"pipeline": map[string]any{
+			"getUser":     nil,
+			"createOrder": nil,
+		},
+	}
+	if !reflect.DeepEqual(got.GetContext().AsMap(), want) {
+		t.Errorf("context = %v, want %v", got.GetContext().AsMap(), want)
+	}
+
+	er.Context = nil
+	if got := storedExecutionResult(er); got.GetContext() != nil {
+		t.Errorf("context = %v, want nil", got.GetContext())
+	}
+}
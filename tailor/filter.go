@@ -0,0 +1,80 @@
+package tailor
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/tailor-platform/patterner/config"
+)
+
+// resourceFilter selects the namespaces, resolvers and types to fetch.
+type resourceFilter struct {
+	namespaces config.Patterns
+	resolvers  config.Patterns
+	types      config.Patterns
+}
+
+func newResourceFilter(f config.Filter) (*resourceFilter, error) {
+	for _, p := range slices.Concat(
+		f.Namespaces.Include, f.Namespaces.Exclude,
+		f.Resolvers.Include, f.Resolvers.Exclude,
+		f.Types.Include, f.Types.Exclude,
+	) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid filter pattern %q: %w", p, err)
+		}
+	}
+	return &resourceFilter{
+		namespaces: f.Namespaces,
+		resolvers:  f.Resolvers,
+		types:      f.Types,
+	}, nil
+}
+
+// namespace reports whether the namespace is selected.
+func (f *resourceFilter) namespace(name string) bool {
+	if f == nil {
+		return true
+	}
+	return selected(f.namespaces, name, name)
+}
+
+// resolver reports whether the resolver of the pipeline namespace is selected.
+func (f *resourceFilter) resolver(namespace, name string) bool {
+	if f == nil {
+		return true
+	}
+	return selected(f.resolvers, namespace, name)
+}
+
+// tailorDBType reports whether the type of the TailorDB namespace is selected.
+func (f *resourceFilter) tailorDBType(namespace, name string) bool {
+	if f == nil {
+		return true
+	}
+	return selected(f.types, namespace, name)
+}
+
+// selected reports whether the resource matches any include pattern (or there are none) and no exclude pattern.
+// Patterns containing a slash are matched against "<namespace>/<name>", others against the name.
+func selected(p config.Patterns, namespace, name string) bool {
+	if matchAny(p.Exclude, namespace, name) {
+		return false
+	}
+	return len(p.Include) == 0 || matchAny(p.Include, namespace, name)
+}
+
+func matchAny(patterns []string, namespace, name string) bool {
+	for _, p := range patterns {
+		target := name
+		if strings.Contains(p, "/") {
+			target = namespace + "/" + name
+		}
+		if ok, _ := path.Match(p, target); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package tailor
+
+import (
+	"testing"
+
+	"github.com/tailor-platform/patterner/config"
+)
+
+func TestResourceFilter(t *testing.T) {
+	f, err := newResourceFilter(config.Filter{
+		Namespaces: config.Patterns{
+			Include: []string{"team-*"},
+			Exclude: []string{"team-legacy"},
+		},
+		Resolvers: config.Patterns{
+			Exclude: []string{"debug*", "team-a/internal*"},
+		},
+		Types: config.Patterns{
+			Include: []string{"Order*", "team-db/User"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		got  bool
+		want bool
+	}{
+		{"included namespace", f.namespace("team-a"), true},
+		{"excluded namespace", f.namespace("team-legacy"), false},
+		{"not included namespace", f.namespace("other"), false},
+		{"resolver without include", f.resolver("team-a", "createOrder"), true},
+		{"excluded resolver by name", f.resolver("team-b", "debugOrder"), false},
+		{"excluded resolver by namespace and name", f.resolver("team-a", "internalSync"), false},
+		{"resolver excluded only in another namespace", f.resolver("team-b", "internalSync"), true},
+		{"included type by name", f.tailorDBType("team-db", "OrderItem"), true},
+		{"included type by namespace and name", f.tailorDBType("team-db", "User"), true},
+		{"type of another namespace", f.tailorDBType("other-db", "User"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceFilter_Nil(t *testing.T) {
+	var f *resourceFilter
+	if !f.namespace("ns") || !f.resolver("ns", "resolver") || !f.tailorDBType("ns", "Type") {
+		t.Error("nil filter should select everything")
+	}
+}
+
+func TestNewResourceFilter_InvalidPattern(t *testing.T) {
+	if _, err := newResourceFilter(config.Filter{
+		Resolvers: config.Patterns{Include: []string{"[invalid"}},
+	}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
@@ -0,0 +1,186 @@
+package tailor
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tailor-platform/patterner/config"
+)
+
+// ManifestFix is a deprecated feature found in a manifest, fixed or not.
+type ManifestFix struct {
+	Line int
+	Rule LintRule
+	// Field is the deprecated field (e.g., PreScript).
+	Field string
+	// Replacement is the field replacing it (e.g., PreHook).
+	Replacement string
+	// Reason is why the field is not fixed. It is empty when the field is fixed.
+	Reason string
+	// Note is what to review in the fix. It is empty when the fix needs no review.
+	Note string
+}
+
+func (f *ManifestFix) Fixed() bool {
+	return f.Reason == ""
+}
+
+type deprecatedField struct {
+	rule        LintRule
+	replacement string
+	// script is the CEL script becoming the same hook as the validation. It is empty for the other fields.
+	script string
+}
+
+// deprecatedFields are the deprecated fields of the CUE manifests detected by Client.Lint.
+var deprecatedFields = map[string]deprecatedField{
+	"PreScript":      {LintRulePipelineDeprecatedFeature, "PreHook", ""},
+	"PostScript":     {LintRulePipelineDeprecatedFeature, "PostHook", ""},
+	"PreValidation":  {LintRulePipelineDeprecatedFeature, "PreHook", "PreScript"},
+	"PostValidation": {LintRulePipelineDeprecatedFeature, "PostHook", "PostScript"},
+	"CreateExpr":     {LintRuleTailorDBDeprecatedFeature, "Create", ""},
+	"UpdateExpr":     {LintRuleTailorDBDeprecatedFeature, "Update", ""},
+}
+
+var (
+	manifestFieldRe = regexp.MustCompile(`^(\s*)(PreScript|PostScript|PreValidation|PostValidation|CreateExpr|UpdateExpr)(\s*:\s*)("(?:[^"\\]|\\.)*")(\s*,?\s*(?://.*)?)$`)
+	anyFieldRe      = regexp.MustCompile(`\b(PreScript|PostScript|PreValidation|PostValidation|CreateExpr|UpdateExpr)\s*:`)
+	keyRe           = regexp.MustCompile(`^(\s*)(\w+)(\s*:\s*)`)
+)
+
+// FixManifest rewrites the deprecated features of the CUE manifest that have mechanical fixes,
+// following the rules of the configuration as Client.Lint does:
+// the CEL scripts become hooks (e.g., PreScript to PreHook: {Expr: ...}), the CEL validations become hooks throwing an error when they fail
+// and the CEL hooks of the TailorDB fields become script hooks (e.g., CreateExpr to Create: {Expr: ...}).
+// It returns the rewritten manifest and the deprecated features found.
+func FixManifest(cfg *config.Config, src []byte) ([]byte, []*ManifestFix) {
+	lines := strings.Split(string(src), "\n")
+	var fixes []*ManifestFix
+	for i, line := range lines {
+		m := manifestFieldRe.FindStringSubmatch(line)
+		if m == nil {
+			if m := anyFieldRe.FindStringSubmatch(line); m != nil && fixEnabled(cfg, deprecatedFields[m[1]].rule) {
+				fixes = append(fixes, &ManifestFix{
+					Line:        i + 1,
+					Rule:        deprecatedFields[m[1]].rule,
+					Field:       m[1],
+					Replacement: deprecatedFields[m[1]].replacement,
+					Reason:      "only a single-line string on its own line is fixed",
+				})
+			}
+			continue
+		}
+		indent, field, sep, quoted, rest := m[1], m[2], m[3], m[4], m[5]
+		df := deprecatedFields[field]
+		if !fixEnabled(cfg, df.rule) {
+			continue
+		}
+		fix := &ManifestFix{
+			Line:        i + 1,
+			Rule:        df.rule,
+			Field:       field,
+			Replacement: df.replacement,
+		}
+		fixes = append(fixes, fix)
+		siblings := siblingKeys(lines, i, indent)
+		if df.script != "" && slices.Contains(siblings, df.script) {
+			fix.Reason = fmt.Sprintf("%s also becomes %s; merge the validation into its hook by hand", df.script, df.replacement)
+			continue
+		}
+		if slices.Contains(siblings, df.replacement) {
+			fix.Reason = fmt.Sprintf("%s is already set", df.replacement)
+			continue
+		}
+		expr, err := strconv.Unquote(quoted)
+		if err != nil {
+			fix.Reason = fmt.Sprintf("failed to parse the string: %v", err)
+			continue
+		}
+		t := TranslateCEL(expr)
+		switch {
+		case t.Err != nil:
+			fix.Reason = t.Err.Error()
+			continue
+		case len(t.Notes) > 0:
+			fix.Reason = "the translation needs a review: " + strings.Join(t.Notes, "; ")
+			continue
+		}
+		js := t.JS
+		if df.script != "" {
+			js, fix.Note = validationHook(js, df.replacement == "PostHook")
+		}
+		lines[i] = fmt.Sprintf("%s%s%s{Expr: %s}%s", indent, df.replacement, alignSeparator(lines, i, indent, field, sep, df.replacement), strconv.Quote(js), rest)
+	}
+	return []byte(strings.Join(lines, "\n")), fixes
+}
+
+// validationHook returns the hook replacing the translated validation, which throws an error when it fails
+// and otherwise passes the input (pre) or the output (post) of the step through, and what to review in it.
+func validationHook(js string, post bool) (string, string) {
+	value, of := "context.args", "input"
+	if post {
+		value, of = "args", "output"
+	}
+	hook := fmt.Sprintf(`(() => { if (!(%s)) throw new Error("validation failed"); return %s })()`, js, value)
+	return hook, fmt.Sprintf("the hook throws an error when the validation fails and returns %s as the %s of the step; check the error message and the returned value", value, of)
+}
+
+// alignSeparator returns the separator of the replacement key, padded to keep the value aligned with the adjacent lines if it is.
+func alignSeparator(lines []string, i int, indent, field, sep, replacement string) string {
+	column := len(indent) + len(field) + len(sep)
+	for _, j := range []int{i - 1, i + 1} {
+		if j < 0 || j >= len(lines) {
+			continue
+		}
+		m := keyRe.FindStringSubmatch(lines[j])
+		if m == nil || m[1] != indent || len(m[0]) != column {
+			continue
+		}
+		return sep + strings.Repeat(" ", len(field)-len(replacement))
+	}
+	return sep
+}
+
+// fixEnabled reports whether the deprecated features of the rule are linted.
+func fixEnabled(cfg *config.Config, rule LintRule) bool {
+	switch rule {
+	case LintRulePipelineDeprecatedFeature:
+		return cfg.Lint.Rules.Pipeline.DeprecatedFeature.Enabled && !cfg.Lint.Rules.Pipeline.DeprecatedFeature.AllowCELScript
+	case LintRuleTailorDBDeprecatedFeature:
+		return cfg.Lint.Rules.TailorDB.DeprecatedFeature.Enabled && !cfg.Lint.Rules.TailorDB.DeprecatedFeature.AllowCELHooks
+	}
+	return false
+}
+
+// siblingKeys returns the keys at the same indentation in the block of the line.
+func siblingKeys(lines []string, i int, indent string) []string {
+	var keys []string
+	collect := func(j int) bool {
+		if strings.TrimSpace(lines[j]) == "" {
+			return true
+		}
+		m := keyRe.FindStringSubmatch(lines[j])
+		lineIndent := lines[j][:len(lines[j])-len(strings.TrimLeft(lines[j], " \t"))]
+		if len(lineIndent) < len(indent) {
+			return false
+		}
+		if m != nil && m[1] == indent {
+			keys = append(keys, m[2])
+		}
+		return true
+	}
+	for j := i - 1; j >= 0; j-- {
+		if !collect(j) {
+			break
+		}
+	}
+	for j := i + 1; j < len(lines); j++ {
+		if !collect(j) {
+			break
+		}
+	}
+	return keys
+}
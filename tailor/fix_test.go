@@ -0,0 +1,143 @@
+package tailor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFixManifest(t *testing.T) {
+	src := `resolver: {
+	Name: "createOrder"
+	Pipelines: [
+		{
+			Name:          "create"
+			PreScript:     "{\"input\": context.args.input}"
+			PostScript:    "size(args.items) > 0"
+			PreValidation: "context.args.input.amount > 0"
+		},
+		{
+			Name:      "get"
+			PreScript: "context.args"
+			PreHook:   {Expr: "context.args"}
+		},
+		{
+			Name:           "validate"
+			PostValidation: "args.id != ''"
+		},
+	]
+}
+
+type: {
+	Fields: {
+		createdAt: {
+			Hooks: {CreateExpr: "now()"}
+		}
+		status: {
+			Hooks: {
+				CreateExpr: "'draft'"
+			}
+		}
+	}
+}`
+	want := `resolver: {
+	Name: "createOrder"
+	Pipelines: [
+		{
+			Name:          "create"
+			PreHook:       {Expr: "({\"input\": context.args.input})"}
+			PostScript:    "size(args.items) > 0"
+			PreValidation: "context.args.input.amount > 0"
+		},
+		{
+			Name:      "get"
+			PreScript: "context.args"
+			PreHook:   {Expr: "context.args"}
+		},
+		{
+			Name:           "validate"
+			PostHook:       {Expr: "(() => { if (!(args.id !== \"\")) throw new Error(\"validation failed\"); return args })()"}
+		},
+	]
+}
+
+type: {
+	Fields: {
+		createdAt: {
+			Hooks: {CreateExpr: "now()"}
+		}
+		status: {
+			Hooks: {
+				Create: {Expr: "\"draft\""}
+			}
+		}
+	}
+}`
+	got, fixes := FixManifest(createTestConfig(t), []byte(src))
+	if string(got) != want {
+		t.Errorf("FixManifest() =\n%s\nwant\n%s", got, want)
+	}
+	wantFixes := []*ManifestFix{
+		{Line: 6, Rule: LintRulePipelineDeprecatedFeature, Field: "PreScript", Replacement: "PreHook"},
+		{Line: 7, Rule: LintRulePipelineDeprecatedFeature, Field: "PostScript", Replacement: "PostHook", Reason: "the translation needs a review: size() of a map is Object.keys(...).length in JavaScript"},
+		{Line: 8, Rule: LintRulePipelineDeprecatedFeature, Field: "PreValidation", Replacement: "PreHook", Reason: "PreHook is already set"},
+		{Line: 12, Rule: LintRulePipelineDeprecatedFeature, Field: "PreScript", Replacement: "PreHook", Reason: "PreHook is already set"},
+		{Line: 17, Rule: LintRulePipelineDeprecatedFeature, Field: "PostValidation", Replacement: "PostHook", Note: "the hook throws an error when the validation fails and returns args as the output of the step; check the error message and the returned value"},
+		{Line: 25, Rule: LintRuleTailorDBDeprecatedFeature, Field: "CreateExpr", Replacement: "Create", Reason: "only a single-line string on its own line is fixed"},
+		{Line: 29, Rule: LintRuleTailorDBDeprecatedFeature, Field: "CreateExpr", Replacement: "Create"},
+	}
+	if !reflect.DeepEqual(fixes, wantFixes) {
+		for _, f := range fixes {
+			t.Logf("%+v", f)
+		}
+		t.Error("unexpected fixes")
+	}
+}
+
+func TestFixManifest_Allowed(t *testing.T) {
+	cfg := createTestConfig(t)
+	cfg.Lint.Rules.Pipeline.DeprecatedFeature.AllowCELScript = true
+	src := "{\n\tPreScript: \"context.args\"\n\tHooks: {\n\t\tCreateExpr: \"_value\"\n\t}\n}"
+	got, fixes := FixManifest(cfg, []byte(src))
+	want := "{\n\tPreScript: \"context.args\"\n\tHooks: {\n\t\tCreate: {Expr: \"_value\"}\n\t}\n}"
+	if string(got) != want {
+		t.Errorf("FixManifest() =\n%s\nwant\n%s", got, want)
+	}
+	if len(fixes) != 1 || fixes[0].Field != "CreateExpr" {
+		t.Errorf("only the TailorDB hook should be fixed: %v", fixes)
+	}
+}
+
+func TestFixManifest_NeedsReview(t *testing.T) {
+	src := "{\n\tPreScript: \"context.args.a == context.args.b\"\n\tPostScript: \"args.tags + args.extra\"\n}"
+	got, fixes := FixManifest(createTestConfig(t), []byte(src))
+	if string(got) != src {
+		t.Errorf("FixManifest() =\n%s\nwant it unchanged", got)
+	}
+	if len(fixes) != 2 {
+		t.Fatalf("FixManifest() returned %d fixes, want 2", len(fixes))
+	}
+	for _, f := range fixes {
+		if f.Fixed() {
+			t.Errorf("%s should need a review", f.Field)
+		}
+	}
+}
+
+func TestFixManifest_ValidationWithScript(t *testing.T) {
+	src := "{\n\tPreValidation: \"context.args.id != ''\"\n\tPreScript:     \"context.args\"\n}"
+	got, fixes := FixManifest(createTestConfig(t), []byte(src))
+	want := "{\n\tPreValidation: \"context.args.id != ''\"\n\tPreHook:       {Expr: \"context.args\"}\n}"
+	if string(got) != want {
+		t.Errorf("FixManifest() =\n%s\nwant\n%s", got, want)
+	}
+	wantFixes := []*ManifestFix{
+		{Line: 2, Rule: LintRulePipelineDeprecatedFeature, Field: "PreValidation", Replacement: "PreHook", Reason: "PreScript also becomes PreHook; merge the validation into its hook by hand"},
+		{Line: 3, Rule: LintRulePipelineDeprecatedFeature, Field: "PreScript", Replacement: "PreHook"},
+	}
+	if !reflect.DeepEqual(fixes, wantFixes) {
+		for _, f := range fixes {
+			t.Logf("%+v", f)
+		}
+		t.Error("unexpected fixes")
+	}
+}
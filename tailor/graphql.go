@@ -0,0 +1,175 @@
+package tailor
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+
+	tailorv1 "buf.build/gen/go/tailor-inc/tailor/protocolbuffers/go/tailor/v1"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// defaultListSize is the estimated number of items of a list field whose size is not given by a literal argument.
+const defaultListSize = 10
+
+var (
+	listSizeArguments = []string{"first", "last", "limit"}
+	connectionFields  = []string{"edges", "collection"}
+)
+
+// OperationAnalysis is the analysis of the GraphQL operation of a pipeline step.
+type OperationAnalysis struct {
+	PipelineNamespaceName string
+	ResolverName          string
+	StepName              string
+	// Depth is the depth of the deepest field of the operation.
+	Depth int
+	// Fields is the number of selected fields.
+	Fields int
+	// ListNesting is the maximum number of list fields nested in each other.
+	ListNesting int
+	// Cost is the estimated number of fields resolved, with each list field multiplying the cost of its selections by its size.
+	Cost int
+}
+
+// analyzeOperations analyzes the GraphQL operations of the pipeline steps.
+func analyzeOperations(resources *Resources) ([]*OperationAnalysis, error) {
+	var analyses []*OperationAnalysis
+	for _, p := range resources.Pipelines {
+		for _, r := range p.Resolvers {
+			for _, s := range r.Steps {
+				if s.Operation.Type != tailorv1.PipelineResolver_OPERATION_TYPE_GRAPHQL {
+					continue
+				}
+				query, err := parser.ParseQuery(&ast.Source{
+					Input: s.Operation.Source,
+				})
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse GraphQL operation in %s/%s step %s: %w", p.NamespaceName, r.Name, s.Name, err)
+				}
+				analyses = append(analyses, analyzeOperation(p.NamespaceName, r.Name, s.Name, query))
+			}
+		}
+	}
+
+	return analyses, nil
+}
+
+func analyzeOperation(namespace, resolver, step string, query *ast.QueryDocument) *OperationAnalysis {
+	a := &OperationAnalysis{
+		PipelineNamespaceName: namespace,
+		ResolverName:          resolver,
+		StepName:              step,
+	}
+	for _, op := range query.Operations {
+		s := analyzeSelectionSet(op.SelectionSet, query.Fragments, defaultListSize, map[string]bool{})
+		a.Depth = max(a.Depth, s.depth)
+		a.Fields += s.fields
+		a.ListNesting = max(a.ListNesting, s.listNesting)
+		a.Cost = addCost(a.Cost, s.cost)
+	}
+	return a
+}
+
+type selectionStats struct {
+	depth       int
+	fields      int
+	listNesting int
+	cost        int
+}
+
+// analyzeSelectionSet analyzes the selection set. parentSize is the list size given by the arguments of the parent field,
+// which is the size of its connection fields (edges, collection).
+func analyzeSelectionSet(set ast.SelectionSet, fragments ast.FragmentDefinitionList, parentSize int, visited map[string]bool) selectionStats {
+	var stats selectionStats
+	add := func(s selectionStats) {
+		stats.depth = max(stats.depth, s.depth)
+		stats.fields += s.fields
+		stats.listNesting = max(stats.listNesting, s.listNesting)
+		stats.cost = addCost(stats.cost, s.cost)
+	}
+	for _, selection := range set {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			size, sized := listSize(sel)
+			if !sized {
+				size = defaultListSize
+			}
+			children := analyzeSelectionSet(sel.SelectionSet, fragments, size, visited)
+			isList := sized && !hasConnectionField(sel.SelectionSet)
+			if slices.Contains(connectionFields, sel.Name) {
+				isList = true
+				size = parentSize
+			}
+			s := selectionStats{
+				depth:       1 + children.depth,
+				fields:      1 + children.fields,
+				listNesting: children.listNesting,
+				cost:        addCost(1, children.cost),
+			}
+			if isList {
+				s.listNesting++
+				s.cost = addCost(1, mulCost(children.cost, size))
+			}
+			add(s)
+		case *ast.InlineFragment:
+			add(analyzeSelectionSet(sel.SelectionSet, fragments, parentSize, visited))
+		case *ast.FragmentSpread:
+			def := fragments.ForName(sel.Name)
+			if def == nil || visited[sel.Name] {
+				continue
+			}
+			visited[sel.Name] = true
+			add(analyzeSelectionSet(def.SelectionSet, fragments, parentSize, visited))
+			delete(visited, sel.Name)
+		}
+	}
+	return stats
+}
+
+// listSize returns the size given by the first, last or limit argument of the field.
+func listSize(f *ast.Field) (int, bool) {
+	for _, name := range listSizeArguments {
+		arg := f.Arguments.ForName(name)
+		if arg == nil {
+			continue
+		}
+		if arg.Value.Kind == ast.IntValue {
+			if n, err := strconv.Atoi(arg.Value.Raw); err == nil {
+				return n, true
+			}
+		}
+		return defaultListSize, true
+	}
+	return 0, false
+}
+
+// addCost adds the non-negative costs, saturating at math.MaxInt so that deeply nested lists stay the most expensive.
+func addCost(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+// mulCost multiplies the cost by the list size, saturating at math.MaxInt. A negative size counts as 0.
+func mulCost(cost, size int) int {
+	if cost <= 0 || size <= 0 {
+		return 0
+	}
+	if cost > math.MaxInt/size {
+		return math.MaxInt
+	}
+	return cost * size
+}
+
+func hasConnectionField(set ast.SelectionSet) bool {
+	for _, selection := range set {
+		if f, ok := selection.(*ast.Field); ok && slices.Contains(connectionFields, f.Name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package tailor
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	tailorv1 "buf.build/gen/go/tailor-inc/tailor/protocolbuffers/go/tailor/v1"
+)
+
+func TestAnalyzeOperations(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   *OperationAnalysis
+	}{
+		{
+			name:   "single object",
+			source: `query { user(id: "1") { id name } }`,
+			want:   &OperationAnalysis{Depth: 2, Fields: 3, ListNesting: 0, Cost: 3},
+		},
+		{
+			name:   "nested connections",
+			source: `query { users(first: 5) { edges { node { id posts(first: 3) { edges { node { id } } } } } } }`,
+			want:   &OperationAnalysis{Depth: 7, Fields: 8, ListNesting: 2, Cost: 52},
+		},
+		{
+			name:   "list with variable size",
+			source: `query($n: Int) { items(limit: $n) { id } }`,
+			want:   &OperationAnalysis{Depth: 2, Fields: 2, ListNesting: 1, Cost: 11},
+		},
+		{
+			name:   "fragment spread",
+			source: `query { users { edges { ...UserEdge } } } fragment UserEdge on UserEdge { node { id } }`,
+			want:   &OperationAnalysis{Depth: 4, Fields: 4, ListNesting: 1, Cost: 22},
+		},
+		{
+			name:   "cost saturates instead of overflowing",
+			source: `query { a(limit: 1000000) { b(limit: 1000000) { c(limit: 1000000) { d(limit: 1000000) { id } } } } }`,
+			want:   &OperationAnalysis{Depth: 5, Fields: 5, ListNesting: 4, Cost: math.MaxInt},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources := &Resources{
+				Pipelines: []*Pipeline{
+					{
+						NamespaceName: "test-namespace",
+						Resolvers: []*PipelineResolver{
+							{
+								Name: "test-resolver",
+								Steps: []*PipelineStep{
+									{
+										Name: "step1",
+										Operation: PipelineStepOperation{
+											Type:   tailorv1.PipelineResolver_OPERATION_TYPE_GRAPHQL,
+											Source: tt.source,
+										},
+									},
+									{
+										Name: "step2",
+										Operation: PipelineStepOperation{
+											Type: tailorv1.PipelineResolver_OPERATION_TYPE_FUNCTION,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			got, err := analyzeOperations(resources)
+			if err != nil {
+				t.Fatalf("analyzeOperations() error = %v", err)
+			}
+			tt.want.PipelineNamespaceName = "test-namespace"
+			tt.want.ResolverName = "test-resolver"
+			tt.want.StepName = "step1"
+			if len(got) != 1 {
+				t.Fatalf("analyzeOperations() returned %d analyses, want 1", len(got))
+			}
+			if !reflect.DeepEqual(got[0], tt.want) {
+				t.Errorf("analyzeOperations() = %+v, want %+v", got[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Lint_MaxDepthAndMaxCost(t *testing.T) {
+	cfg := createTestConfig(t)
+	cfg.Lint.Rules.Pipeline.MaxDepth.Enabled = true
+	cfg.Lint.Rules.Pipeline.MaxDepth.Max = 5
+	cfg.Lint.Rules.Pipeline.MaxCost.Enabled = true
+	cfg.Lint.Rules.Pipeline.MaxCost.Max = 50
+
+	resources := &Resources{
+		Pipelines: []*Pipeline{
+			{
+				NamespaceName: "test-ns",
+				Resolvers: []*PipelineResolver{
+					{
+						Name: "testResolver",
+						Steps: []*PipelineStep{
+							{
+								Name: "nestedStep",
+								Operation: PipelineStepOperation{
+									Type:   tailorv1.PipelineResolver_OPERATION_TYPE_GRAPHQL,
+									Source: "query { users(first: 5) { edges { node { id posts(first: 3) { edges { node { id } } } } } } }",
+								},
+							},
+							{
+								Name: "simpleStep",
+								Operation: PipelineStepOperation{
+									Type:   tailorv1.PipelineResolver_OPERATION_TYPE_GRAPHQL,
+									Source: "query { user(id: \"1\") { id } }",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	client, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	warns, err := client.Lint(resources)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(warns) != 2 {
+		t.Fatalf("Expected 2 warnings, got %d", len(warns))
+	}
+	want := []struct {
+		rule    LintRule
+		message string
+	}{
+		{LintRulePipelineMaxDepth, "GraphQL operation is too deep (7 > 5)"},
+		{LintRulePipelineMaxCost, "GraphQL operation is too expensive (estimated cost 52 > 50, 2 nested lists)"},
+	}
+	for i, w := range want {
+		if warns[i].Rule != w.rule {
+			t.Errorf("Expected warning rule %s, got %s", w.rule, warns[i].Rule)
+		}
+		if warns[i].Message != w.message {
+			t.Errorf("Expected warning message %q, got %q", w.message, warns[i].Message)
+		}
+		if warns[i].Name != "test-ns/testResolver step nestedStep" {
+			t.Errorf("Expected warning name %q, got %q", "test-ns/testResolver step nestedStep", warns[i].Name)
+		}
+	}
+}
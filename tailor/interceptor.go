@@ -0,0 +1,101 @@
+package tailor
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"connectrpc.com/connect"
+)
+
+const (
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 10 * time.Second
+)
+
+// apiInterceptor bounds the number of concurrent API calls, applies a timeout to each call
+// and retries the calls failed with Unavailable or ResourceExhausted with exponential backoff and jitter.
+type apiInterceptor struct {
+	// sem is nil when the concurrency is unlimited.
+	sem        chan struct{}
+	timeout    time.Duration
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+}
+
+func newAPIInterceptor(concurrency int, timeout time.Duration, maxRetries int) *apiInterceptor {
+	i := &apiInterceptor{
+		timeout:    timeout,
+		maxRetries: maxRetries,
+		baseDelay:  defaultRetryBaseDelay,
+		maxDelay:   defaultRetryMaxDelay,
+	}
+	if concurrency > 0 {
+		i.sem = make(chan struct{}, concurrency)
+	}
+	return i
+}
+
+func (i *apiInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		for attempt := 0; ; attempt++ {
+			res, err := i.call(ctx, next, req)
+			if err == nil || attempt >= i.maxRetries || !retryable(err) {
+				return res, err
+			}
+			select {
+			case <-ctx.Done():
+				return nil, errors.Join(err, ctx.Err())
+			case <-time.After(i.backoff(attempt)):
+			}
+		}
+	}
+}
+
+func (i *apiInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *apiInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+// call calls the API once, holding a slot of the concurrency limit during the call.
+func (i *apiInterceptor) call(ctx context.Context, next connect.UnaryFunc, req connect.AnyRequest) (connect.AnyResponse, error) {
+	if i.sem != nil {
+		select {
+		case i.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		defer func() { <-i.sem }()
+	}
+	if i.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, i.timeout)
+		defer cancel()
+	}
+	return next(ctx, req)
+}
+
+// backoff returns the delay before the retry following the attempt.
+// The delay is randomized between half and all of the exponential delay,
+// so that the calls failed at the same time (e.g., of concurrent workspaces) are not retried in lockstep.
+func (i *apiInterceptor) backoff(attempt int) time.Duration {
+	d := i.baseDelay << attempt
+	if d <= 0 || d > i.maxDelay {
+		d = i.maxDelay
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+func retryable(err error) bool {
+	switch connect.CodeOf(err) {
+	case connect.CodeUnavailable, connect.CodeResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
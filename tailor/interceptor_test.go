@@ -0,0 +1,135 @@
+package tailor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+)
+
+func TestAPIInterceptor_Retry(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int
+		code       connect.Code
+		maxRetries int
+		wantCalls  int
+		wantErr    bool
+	}{
+		{
+			name:       "success",
+			failures:   0,
+			maxRetries: 3,
+			wantCalls:  1,
+		},
+		{
+			name:       "retry unavailable",
+			failures:   2,
+			code:       connect.CodeUnavailable,
+			maxRetries: 3,
+			wantCalls:  3,
+		},
+		{
+			name:       "retry resource exhausted",
+			failures:   1,
+			code:       connect.CodeResourceExhausted,
+			maxRetries: 3,
+			wantCalls:  2,
+		},
+		{
+			name:       "give up after max retries",
+			failures:   5,
+			code:       connect.CodeUnavailable,
+			maxRetries: 2,
+			wantCalls:  3,
+			wantErr:    true,
+		},
+		{
+			name:       "no retry on permanent error",
+			failures:   1,
+			code:       connect.CodePermissionDenied,
+			maxRetries: 3,
+			wantCalls:  1,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := newAPIInterceptor(0, 0, tt.maxRetries)
+			i.baseDelay = time.Millisecond
+			var calls int
+			next := func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+				calls++
+				if calls <= tt.failures {
+					return nil, connect.NewError(tt.code, errors.New("failed"))
+				}
+				return connect.NewResponse(&struct{}{}), nil
+			}
+			_, err := i.WrapUnary(next)(context.Background(), connect.NewRequest(&struct{}{}))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("got %d calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestAPIInterceptor_Concurrency(t *testing.T) {
+	i := newAPIInterceptor(2, 0, 0)
+	var running, maxRunning atomic.Int32
+	next := func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return connect.NewResponse(&struct{}{}), nil
+	}
+	unary := i.WrapUnary(next)
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			if _, err := unary(context.Background(), connect.NewRequest(&struct{}{})); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+	if got := maxRunning.Load(); got > 2 {
+		t.Errorf("got %d concurrent calls, want at most 2", got)
+	}
+}
+
+func TestAPIInterceptor_Timeout(t *testing.T) {
+	i := newAPIInterceptor(0, 10*time.Millisecond, 0)
+	next := func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	_, err := i.WrapUnary(next)(context.Background(), connect.NewRequest(&struct{}{}))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestAPIInterceptor_Backoff(t *testing.T) {
+	i := newAPIInterceptor(0, 0, 10)
+	want := []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for attempt, w := range want {
+		for range 100 {
+			if got := i.backoff(attempt); got < w/2 || got > w {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", attempt, got, w/2, w)
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package tailor
 
 import (
 	"fmt"
+	"path"
 	"regexp"
 	"slices"
 
@@ -18,10 +19,32 @@ const (
 	LintTargetTypeStateFlow LintTargetType = "stateflow"
 )
 
+// LintRule is the name of the rule that produced a warning, in the form of "<target type>/<rule>" matching the config keys.
+type LintRule string
+
+const (
+	LintRulePipelineDeprecatedFeature     LintRule = "pipeline/deprecatedFeature"
+	LintRulePipelineInsecureAuthorization LintRule = "pipeline/insecureAuthorization"
+	LintRulePipelineStepCount             LintRule = "pipeline/stepCount"
+	LintRulePipelineMultipleMutations     LintRule = "pipeline/multipleMutations"
+	LintRulePipelineQueryBeforeMutation   LintRule = "pipeline/queryBeforeMutation"
+	LintRulePipelineComplexity            LintRule = "pipeline/complexity"
+	LintRulePipelineMaxDepth              LintRule = "pipeline/maxDepth"
+	LintRulePipelineMaxCost               LintRule = "pipeline/maxCost"
+	LintRulePipelineFetchError            LintRule = "pipeline/fetchError"
+	LintRuleTailorDBDeprecatedFeature     LintRule = "tailordb/deprecatedFeature"
+	LintRuleTailorDBFetchError            LintRule = "tailordb/fetchError"
+	LintRuleStateFlowDeprecatedFeature    LintRule = "stateflow/deprecatedFeature"
+)
+
 type LintWarn struct {
-	Type    LintTargetType
-	Name    string
-	Message string
+	Type LintTargetType
+	Rule LintRule
+	Name string
+	// Namespace and Resource identify the resolver or type the warning belongs to. Resource is empty for StateFlow warnings.
+	Namespace string
+	Resource  string
+	Message   string
 }
 
 var draftMutationPrefixRe = regexp.MustCompile(`^(appendDraft|confirmDraft|cancelDraft)`)
@@ -29,33 +52,40 @@ var stateFlowMutations = []string{"newState", "moveState"}
 
 func (c *Client) Lint(resources *Resources) ([]*LintWarn, error) {
 	var warns []*LintWarn
-	var typeNames []string
+	typeNames := resources.tailorDBTypeNames()
 
 	// TailorDB Linting
 	for _, db := range resources.TailorDBs {
 		for _, t := range db.Types {
-			typeNames = append(typeNames, t.Name)
-
 			if c.cfg.Lint.Rules.TailorDB.DeprecatedFeature.Enabled {
 				if !c.cfg.Lint.Rules.TailorDB.DeprecatedFeature.AllowDraft && t.Draft {
 					warns = append(warns, &LintWarn{
-						Type:    LintTargetTypeTailorDB,
-						Name:    fmt.Sprintf("%s/%s", db.NamespaceName, t.Name),
-						Message: "Draft feature is deprecated",
+						Type:      LintTargetTypeTailorDB,
+						Rule:      LintRuleTailorDBDeprecatedFeature,
+						Name:      fmt.Sprintf("%s/%s", db.NamespaceName, t.Name),
+						Namespace: db.NamespaceName,
+						Resource:  t.Name,
+						Message:   "Draft feature is deprecated",
 					})
 				}
 				if !c.cfg.Lint.Rules.TailorDB.DeprecatedFeature.AllowTypePermission && t.TypePermission != nil {
 					warns = append(warns, &LintWarn{
-						Type:    LintTargetTypeTailorDB,
-						Name:    fmt.Sprintf("%s/%s", db.NamespaceName, t.Name),
-						Message: "Type-level permission is deprecated. Use `Permission` or `GQLPermission` instead",
+						Type:      LintTargetTypeTailorDB,
+						Rule:      LintRuleTailorDBDeprecatedFeature,
+						Name:      fmt.Sprintf("%s/%s", db.NamespaceName, t.Name),
+						Namespace: db.NamespaceName,
+						Resource:  t.Name,
+						Message:   "Type-level permission is deprecated. Use `Permission` or `GQLPermission` instead",
 					})
 				}
 				if !c.cfg.Lint.Rules.TailorDB.DeprecatedFeature.AllowRecordPermission && t.RecordPermission != nil {
 					warns = append(warns, &LintWarn{
-						Type:    LintTargetTypeTailorDB,
-						Name:    fmt.Sprintf("%s/%s", db.NamespaceName, t.Name),
-						Message: "Record-level permission is deprecated. Use `Permission` or `GQLPermission` instead",
+						Type:      LintTargetTypeTailorDB,
+						Rule:      LintRuleTailorDBDeprecatedFeature,
+						Name:      fmt.Sprintf("%s/%s", db.NamespaceName, t.Name),
+						Namespace: db.NamespaceName,
+						Resource:  t.Name,
+						Message:   "Record-level permission is deprecated. Use `Permission` or `GQLPermission` instead",
 					})
 				}
 			}
@@ -64,9 +94,12 @@ func (c *Client) Lint(resources *Resources) ([]*LintWarn, error) {
 				for _, f := range t.Fields {
 					if f.Hooks.CreateExpr != "" || f.Hooks.UpdateExpr != "" {
 						warns = append(warns, &LintWarn{
-							Type:    LintTargetTypeTailorDB,
-							Name:    fmt.Sprintf("%s/%s field %s", db.NamespaceName, t.Name, f.Name),
-							Message: "Hooks `create_expr` and `update_expr` are deprecated. Use `create` or `update` instead",
+							Type:      LintTargetTypeTailorDB,
+							Rule:      LintRuleTailorDBDeprecatedFeature,
+							Name:      fmt.Sprintf("%s/%s field %s", db.NamespaceName, t.Name, f.Name),
+							Namespace: db.NamespaceName,
+							Resource:  t.Name,
+							Message:   "Hooks `create_expr` and `update_expr` are deprecated. Use `create` or `update` instead",
 						})
 					}
 				}
@@ -80,9 +113,12 @@ func (c *Client) Lint(resources *Resources) ([]*LintWarn, error) {
 			// Pipeline/InsecureAuthorization
 			if c.cfg.Lint.Rules.Pipeline.InsecureAuthorization.Enabled && (r.Authorization == "true" || r.Authorization == "true==true") {
 				warns = append(warns, &LintWarn{
-					Type:    LintTargetTypePipeline,
-					Name:    fmt.Sprintf("%s/%s", p.NamespaceName, r.Name),
-					Message: "resolver allows insecure authorization",
+					Type:      LintTargetTypePipeline,
+					Rule:      LintRulePipelineInsecureAuthorization,
+					Name:      fmt.Sprintf("%s/%s", p.NamespaceName, r.Name),
+					Namespace: p.NamespaceName,
+					Resource:  r.Name,
+					Message:   "resolver allows insecure authorization",
 				})
 			}
 
@@ -90,12 +126,33 @@ func (c *Client) Lint(resources *Resources) ([]*LintWarn, error) {
 			// Pipeline/StepCount
 			if c.cfg.Lint.Rules.Pipeline.StepCount.Enabled && stepCount > c.cfg.Lint.Rules.Pipeline.StepCount.Max {
 				warns = append(warns, &LintWarn{
-					Type:    LintTargetTypePipeline,
-					Name:    fmt.Sprintf("%s/%s", p.NamespaceName, r.Name),
-					Message: fmt.Sprintf("resolver has too many steps (%d > %d)", stepCount, c.cfg.Lint.Rules.Pipeline.StepCount.Max),
+					Type:      LintTargetTypePipeline,
+					Rule:      LintRulePipelineStepCount,
+					Name:      fmt.Sprintf("%s/%s", p.NamespaceName, r.Name),
+					Namespace: p.NamespaceName,
+					Resource:  r.Name,
+					Message:   fmt.Sprintf("resolver has too many steps (%d > %d)", stepCount, c.cfg.Lint.Rules.Pipeline.StepCount.Max),
 				})
 			}
 
+			// Pipeline/Complexity
+			if c.cfg.Lint.Rules.Pipeline.Complexity.Enabled {
+				rc, err := resolverComplexity(p.NamespaceName, r, typeNames)
+				if err != nil {
+					return nil, err
+				}
+				if score := rc.Score(); score > c.cfg.Lint.Rules.Pipeline.Complexity.Max {
+					warns = append(warns, &LintWarn{
+						Type:      LintTargetTypePipeline,
+						Rule:      LintRulePipelineComplexity,
+						Name:      fmt.Sprintf("%s/%s", p.NamespaceName, r.Name),
+						Namespace: p.NamespaceName,
+						Resource:  r.Name,
+						Message:   fmt.Sprintf("resolver is too complex (complexity %d > %d)", score, c.cfg.Lint.Rules.Pipeline.Complexity.Max),
+					})
+				}
+			}
+
 			var operations []string
 			for _, s := range r.Steps {
 				if s.Operation.Type == tailorv1.PipelineResolver_OPERATION_TYPE_GRAPHQL {
@@ -105,6 +162,29 @@ func (c *Client) Lint(resources *Resources) ([]*LintWarn, error) {
 					if err != nil {
 						return nil, fmt.Errorf("failed to parse GraphQL operation in %s/%s step %s: %w", p.NamespaceName, r.Name, s.Name, err)
 					}
+					analysis := analyzeOperation(p.NamespaceName, r.Name, s.Name, query)
+					// Pipeline/MaxDepth
+					if c.cfg.Lint.Rules.Pipeline.MaxDepth.Enabled && analysis.Depth > c.cfg.Lint.Rules.Pipeline.MaxDepth.Max {
+						warns = append(warns, &LintWarn{
+							Type:      LintTargetTypePipeline,
+							Rule:      LintRulePipelineMaxDepth,
+							Name:      fmt.Sprintf("%s/%s step %s", p.NamespaceName, r.Name, s.Name),
+							Namespace: p.NamespaceName,
+							Resource:  r.Name,
+							Message:   fmt.Sprintf("GraphQL operation is too deep (%d > %d)", analysis.Depth, c.cfg.Lint.Rules.Pipeline.MaxDepth.Max),
+						})
+					}
+					// Pipeline/MaxCost
+					if c.cfg.Lint.Rules.Pipeline.MaxCost.Enabled && analysis.Cost > c.cfg.Lint.Rules.Pipeline.MaxCost.Max {
+						warns = append(warns, &LintWarn{
+							Type:      LintTargetTypePipeline,
+							Rule:      LintRulePipelineMaxCost,
+							Name:      fmt.Sprintf("%s/%s step %s", p.NamespaceName, r.Name, s.Name),
+							Namespace: p.NamespaceName,
+							Resource:  r.Name,
+							Message:   fmt.Sprintf("GraphQL operation is too expensive (estimated cost %d > %d, %d nested lists)", analysis.Cost, c.cfg.Lint.Rules.Pipeline.MaxCost.Max, analysis.ListNesting),
+						})
+					}
 					for _, op := range query.Operations {
 						operations = append(operations, string(op.Operation))
 						for _, selection := range op.SelectionSet {
@@ -115,9 +195,12 @@ func (c *Client) Lint(resources *Resources) ([]*LintWarn, error) {
 									if !c.cfg.Lint.Rules.Pipeline.DeprecatedFeature.AllowStateFlow {
 										if slices.Contains(stateFlowMutations, sel.Name) {
 											warns = append(warns, &LintWarn{
-												Type:    LintTargetTypePipeline,
-												Name:    fmt.Sprintf("%s/%s step %s", p.NamespaceName, r.Name, s.Name),
-												Message: fmt.Sprintf("StateFlow feature is deprecated (found usage of %s)", sel.Name),
+												Type:      LintTargetTypePipeline,
+												Rule:      LintRulePipelineDeprecatedFeature,
+												Name:      fmt.Sprintf("%s/%s step %s", p.NamespaceName, r.Name, s.Name),
+												Namespace: p.NamespaceName,
+												Resource:  r.Name,
+												Message:   fmt.Sprintf("StateFlow feature is deprecated (found usage of %s)", sel.Name),
 											})
 										}
 									}
@@ -127,9 +210,12 @@ func (c *Client) Lint(resources *Resources) ([]*LintWarn, error) {
 										if replaced := draftMutationPrefixRe.ReplaceAllString(sel.Name, ""); replaced != sel.Name {
 											if slices.Contains(typeNames, replaced) {
 												warns = append(warns, &LintWarn{
-													Type:    LintTargetTypePipeline,
-													Name:    fmt.Sprintf("%s/%s step %s", p.NamespaceName, r.Name, s.Name),
-													Message: fmt.Sprintf("Draft feature is deprecated (found usage of %s)", sel.Name),
+													Type:      LintTargetTypePipeline,
+													Rule:      LintRulePipelineDeprecatedFeature,
+													Name:      fmt.Sprintf("%s/%s step %s", p.NamespaceName, r.Name, s.Name),
+													Namespace: p.NamespaceName,
+													Resource:  r.Name,
+													Message:   fmt.Sprintf("Draft feature is deprecated (found usage of %s)", sel.Name),
 												})
 											}
 										}
@@ -142,30 +228,42 @@ func (c *Client) Lint(resources *Resources) ([]*LintWarn, error) {
 				if c.cfg.Lint.Rules.Pipeline.DeprecatedFeature.Enabled && !c.cfg.Lint.Rules.Pipeline.DeprecatedFeature.AllowCELScript {
 					if s.PreValidation != "" {
 						warns = append(warns, &LintWarn{
-							Type:    LintTargetTypePipeline,
-							Name:    fmt.Sprintf("%s/%s step %s", p.NamespaceName, r.Name, s.Name),
-							Message: "`pre_validation` is deprecated. Use `pre_hook` instead.",
+							Type:      LintTargetTypePipeline,
+							Rule:      LintRulePipelineDeprecatedFeature,
+							Name:      fmt.Sprintf("%s/%s step %s", p.NamespaceName, r.Name, s.Name),
+							Namespace: p.NamespaceName,
+							Resource:  r.Name,
+							Message:   "`pre_validation` is deprecated. Use `pre_hook` instead.",
 						})
 					}
 					if s.PreScript != "" {
 						warns = append(warns, &LintWarn{
-							Type:    LintTargetTypePipeline,
-							Name:    fmt.Sprintf("%s/%s step %s", p.NamespaceName, r.Name, s.Name),
-							Message: "`pre_script` is deprecated. Use `pre_hook` instead.",
+							Type:      LintTargetTypePipeline,
+							Rule:      LintRulePipelineDeprecatedFeature,
+							Name:      fmt.Sprintf("%s/%s step %s", p.NamespaceName, r.Name, s.Name),
+							Namespace: p.NamespaceName,
+							Resource:  r.Name,
+							Message:   "`pre_script` is deprecated. Use `pre_hook` instead.",
 						})
 					}
 					if s.PostScript != "" {
 						warns = append(warns, &LintWarn{
-							Type:    LintTargetTypePipeline,
-							Name:    fmt.Sprintf("%s/%s step %s", p.NamespaceName, r.Name, s.Name),
-							Message: "`post_script` is deprecated. Use `post_hook` instead.",
+							Type:      LintTargetTypePipeline,
+							Rule:      LintRulePipelineDeprecatedFeature,
+							Name:      fmt.Sprintf("%s/%s step %s", p.NamespaceName, r.Name, s.Name),
+							Namespace: p.NamespaceName,
+							Resource:  r.Name,
+							Message:   "`post_script` is deprecated. Use `post_hook` instead.",
 						})
 					}
 					if s.PostValidation != "" {
 						warns = append(warns, &LintWarn{
-							Type:    LintTargetTypePipeline,
-							Name:    fmt.Sprintf("%s/%s step %s", p.NamespaceName, r.Name, s.Name),
-							Message: "`post_validation` is deprecated. Use `post_hook` instead.",
+							Type:      LintTargetTypePipeline,
+							Rule:      LintRulePipelineDeprecatedFeature,
+							Name:      fmt.Sprintf("%s/%s step %s", p.NamespaceName, r.Name, s.Name),
+							Namespace: p.NamespaceName,
+							Resource:  r.Name,
+							Message:   "`post_validation` is deprecated. Use `post_hook` instead.",
 						})
 					}
 				}
@@ -179,24 +277,50 @@ func (c *Client) Lint(resources *Resources) ([]*LintWarn, error) {
 				}
 				if count > 1 {
 					warns = append(warns, &LintWarn{
-						Type:    LintTargetTypePipeline,
-						Name:    fmt.Sprintf("%s/%s", p.NamespaceName, r.Name),
-						Message: "Resolver has multiple mutations. Because transactions are not applied between steps, it is recommended to use transaction within function.",
+						Type:      LintTargetTypePipeline,
+						Rule:      LintRulePipelineMultipleMutations,
+						Name:      fmt.Sprintf("%s/%s", p.NamespaceName, r.Name),
+						Namespace: p.NamespaceName,
+						Resource:  r.Name,
+						Message:   "Resolver has multiple mutations. Because transactions are not applied between steps, it is recommended to use transaction within function.",
 					})
 				}
 			}
 			if c.cfg.Lint.Rules.Pipeline.QueryBeforeMutation.Enabled {
 				if slices.Contains(operations, "mutation") && slices.Contains(operations, "query") && slices.Index(operations, "mutation") > slices.Index(operations, "query") {
 					warns = append(warns, &LintWarn{
-						Type:    LintTargetTypePipeline,
-						Name:    fmt.Sprintf("%s/%s", p.NamespaceName, r.Name),
-						Message: "Resolver has query before mutation. Because transactions are not applied between steps, it is recommended to use transaction within function.",
+						Type:      LintTargetTypePipeline,
+						Rule:      LintRulePipelineQueryBeforeMutation,
+						Name:      fmt.Sprintf("%s/%s", p.NamespaceName, r.Name),
+						Namespace: p.NamespaceName,
+						Resource:  r.Name,
+						Message:   "Resolver has query before mutation. Because transactions are not applied between steps, it is recommended to use transaction within function.",
 					})
 				}
 			}
 		}
 	}
 
+	// Fetch errors
+	for _, fe := range resources.FetchErrors {
+		rule := LintRulePipelineFetchError
+		if fe.Type == LintTargetTypeTailorDB {
+			rule = LintRuleTailorDBFetchError
+		}
+		name := fe.Namespace
+		if fe.Resource != "" {
+			name = fmt.Sprintf("%s/%s", fe.Namespace, fe.Resource)
+		}
+		warns = append(warns, &LintWarn{
+			Type:      fe.Type,
+			Rule:      rule,
+			Name:      name,
+			Namespace: fe.Namespace,
+			Resource:  fe.Resource,
+			Message:   fmt.Sprintf("failed to fetch: %v", fe.Err),
+		})
+	}
+
 	// StateFlow Linting
 	if c.cfg.Lint.Rules.StateFlow.DeprecatedFeature.Enabled {
 		for _, sf := range resources.StateFlows {
@@ -204,12 +328,52 @@ func (c *Client) Lint(resources *Resources) ([]*LintWarn, error) {
 				continue
 			}
 			warns = append(warns, &LintWarn{
-				Type:    LintTargetTypeStateFlow,
-				Name:    sf.NamespaceName,
-				Message: "StateFlow is deprecated",
+				Type:      LintTargetTypeStateFlow,
+				Rule:      LintRuleStateFlowDeprecatedFeature,
+				Name:      sf.NamespaceName,
+				Namespace: sf.NamespaceName,
+				Message:   "StateFlow is deprecated",
 			})
 		}
 	}
 
-	return warns, nil
+	return c.withoutIgnored(warns)
+}
+
+// withoutIgnored returns the warnings not matching any ignore entry of the configuration.
+func (c *Client) withoutIgnored(warns []*LintWarn) ([]*LintWarn, error) {
+	if len(c.cfg.Lint.Ignore) == 0 {
+		return warns, nil
+	}
+	var filtered []*LintWarn
+	for _, w := range warns {
+		ignored, err := c.ignored(w)
+		if err != nil {
+			return nil, err
+		}
+		if !ignored {
+			filtered = append(filtered, w)
+		}
+	}
+	return filtered, nil
+}
+
+func (c *Client) ignored(w *LintWarn) (bool, error) {
+	resource := w.Namespace
+	if w.Resource != "" {
+		resource = fmt.Sprintf("%s/%s", w.Namespace, w.Resource)
+	}
+	for _, ig := range c.cfg.Lint.Ignore {
+		if ig.Rule != string(w.Rule) {
+			continue
+		}
+		ok, err := path.Match(ig.Resource, resource)
+		if err != nil {
+			return false, fmt.Errorf("invalid lint.ignore resource pattern %q: %w", ig.Resource, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
 }
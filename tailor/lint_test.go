@@ -1,6 +1,8 @@
 package tailor
 
 import (
+	"errors"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -391,9 +393,11 @@ func TestClient_Lint_GraphQLParsing(t *testing.T) {
 		name         string
 		graphqlQuery string
 		typeNames    []string
-		configMod    func(*config.Config)
-		expectedMsgs []string
-		wantError    bool
+		// filteredTypeNames are the names of the types filtered out.
+		filteredTypeNames []string
+		configMod         func(*config.Config)
+		expectedMsgs      []string
+		wantError         bool
 	}{
 		{
 			name:         "valid GraphQL query",
@@ -429,6 +433,16 @@ func TestClient_Lint_GraphQLParsing(t *testing.T) {
 			},
 			expectedMsgs: []string{"Draft feature is deprecated (found usage of appendDraftUser)"},
 		},
+		{
+			name:              "draft mutation of a filtered type warning",
+			graphqlQuery:      "mutation { appendDraftUser(input: {name: \"test\"}) { id } }",
+			filteredTypeNames: []string{"User"},
+			configMod: func(c *config.Config) {
+				c.Lint.Rules.Pipeline.DeprecatedFeature.Enabled = true
+				c.Lint.Rules.Pipeline.DeprecatedFeature.AllowDraft = false
+			},
+			expectedMsgs: []string{"Draft feature is deprecated (found usage of appendDraftUser)"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -469,6 +483,7 @@ func TestClient_Lint_GraphQLParsing(t *testing.T) {
 						}(),
 					},
 				},
+				filteredTypeNames: tt.filteredTypeNames,
 			}
 
 			client, err := New(cfg)
@@ -649,6 +664,9 @@ func TestClient_Lint_QueryBeforeMutation(t *testing.T) {
 	if !strings.Contains(warns[0].Message, "Resolver has query before mutation") {
 		t.Errorf("Expected warning message to contain 'Resolver has query before mutation', got '%s'", warns[0].Message)
 	}
+	if warns[0].Rule != LintRulePipelineQueryBeforeMutation {
+		t.Errorf("Expected warning rule %s, got %s", LintRulePipelineQueryBeforeMutation, warns[0].Rule)
+	}
 }
 
 func TestLintWarn_String(t *testing.T) {
@@ -682,3 +700,80 @@ func TestLintTargetTypes(t *testing.T) {
 		t.Errorf("Expected LintTargetTypeStateFlow to be 'stateflow', got '%s'", string(LintTargetTypeStateFlow))
 	}
 }
+
+func TestClient_Lint_FetchErrors(t *testing.T) {
+	cfg := createTestConfig(t)
+	resources := &Resources{
+		FetchErrors: []*FetchError{
+			{Type: LintTargetTypePipeline, Namespace: "test-ns", Resource: "brokenResolver", Err: errors.New("internal error")},
+			{Type: LintTargetTypeTailorDB, Namespace: "test-db", Err: errors.New("unavailable")},
+		},
+	}
+
+	client, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	warns, err := client.Lint(resources)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []*LintWarn{
+		{
+			Type:      LintTargetTypePipeline,
+			Rule:      LintRulePipelineFetchError,
+			Name:      "test-ns/brokenResolver",
+			Namespace: "test-ns",
+			Resource:  "brokenResolver",
+			Message:   "failed to fetch: internal error",
+		},
+		{
+			Type:      LintTargetTypeTailorDB,
+			Rule:      LintRuleTailorDBFetchError,
+			Name:      "test-db",
+			Namespace: "test-db",
+			Message:   "failed to fetch: unavailable",
+		},
+	}
+	if !reflect.DeepEqual(warns, want) {
+		t.Errorf("Lint() = %v, want %v", warns, want)
+	}
+}
+
+func TestClient_Lint_Ignore(t *testing.T) {
+	cfg := createTestConfig(t)
+	cfg.Lint.Ignore = []config.LintIgnore{
+		{Rule: "pipeline/fetchError", Resource: "test-ns/broken*"},
+		{Rule: "pipeline/fetchError", Resource: "other-ns/*"},
+		{Rule: "pipeline/stepCount", Resource: "test-db"},
+	}
+	resources := &Resources{
+		FetchErrors: []*FetchError{
+			{Type: LintTargetTypePipeline, Namespace: "test-ns", Resource: "brokenResolver", Err: errors.New("internal error")},
+			{Type: LintTargetTypePipeline, Namespace: "test-ns", Resource: "anotherResolver", Err: errors.New("internal error")},
+			{Type: LintTargetTypeTailorDB, Namespace: "test-db", Err: errors.New("unavailable")},
+		},
+	}
+
+	client, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	warns, err := client.Lint(resources)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var got []string
+	for _, w := range warns {
+		got = append(got, w.Name)
+	}
+	want := []string{"test-ns/anotherResolver", "test-db"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint() = %v, want %v", got, want)
+	}
+
+	cfg.Lint.Ignore = []config.LintIgnore{{Rule: "pipeline/fetchError", Resource: "[invalid"}}
+	if _, err := client.Lint(resources); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
@@ -0,0 +1,265 @@
+package tailor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tailor-platform/patterner/config"
+	"github.com/tailor-platform/patterner/version"
+)
+
+const (
+	deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+	// defaultDeviceInterval is the polling interval in seconds when the authorization server gives none.
+	defaultDeviceInterval = 5
+	// defaultDeviceExpiresIn is the lifetime of the device code in seconds when the authorization server gives none.
+	defaultDeviceExpiresIn = 600
+)
+
+// deviceIntervalUnit is the unit of the polling interval and the lifetime of the device code, shortened in tests.
+var deviceIntervalUnit = time.Second
+
+// DeviceAuthorization is the code for the user to enter at the verification URI to log in.
+type DeviceAuthorization struct {
+	UserCode        string
+	VerificationURI string
+	// VerificationURIComplete is the verification URI including the user code. It is empty if not supported.
+	VerificationURIComplete string
+
+	deviceCode string
+	expiresIn  int
+	interval   int
+}
+
+// loginCredential is the credential of the user logged in with patterner login.
+type loginCredential struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitzero"`
+	// ClientID is the client ID the user logged in with, to refresh the token with.
+	ClientID string `json:"client_id,omitempty"`
+}
+
+// Login logs in as a user with the OAuth 2.0 device authorization grant (RFC 8628) and saves the credential of the platform,
+// which the commands use when no other credential is given. prompt is called with the code for the user to enter.
+// It returns the path of the credentials file.
+func Login(ctx context.Context, cfg config.Auth, prompt func(*DeviceAuthorization)) (string, error) {
+	if cfg.ClientID == "" {
+		return "", errors.New("auth.clientID is required to log in with the device authorization flow")
+	}
+	path, err := credentialsPath(cfg)
+	if err != nil {
+		return "", err
+	}
+	baseURL := platformBaseURL()
+	deviceURL := cfg.DeviceAuthorizationURL
+	if deviceURL == "" {
+		deviceURL = strings.TrimSuffix(baseURL, "/") + "/oauth2/device_authorization"
+	}
+	a, err := requestDeviceAuthorization(ctx, deviceURL, cfg.ClientID)
+	if err != nil {
+		return "", err
+	}
+	prompt(a)
+	res, err := pollDeviceToken(ctx, tokenEndpoint(cfg, baseURL), cfg.ClientID, a)
+	if err != nil {
+		return "", err
+	}
+	if err := saveCredential(path, baseURL, &loginCredential{
+		AccessToken:  res.AccessToken,
+		RefreshToken: res.RefreshToken,
+		Expiry:       res.expiry(),
+		ClientID:     cfg.ClientID,
+	}); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func requestDeviceAuthorization(ctx context.Context, deviceURL, clientID string) (*DeviceAuthorization, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, deviceURL, strings.NewReader(url.Values{"client_id": {clientID}}.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	res, err := tokenHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("device authorization endpoint returned %s: %s", res.Status, strings.TrimSpace(string(b)))
+	}
+	var dr struct {
+		DeviceCode              string `json:"device_code"`
+		UserCode                string `json:"user_code"`
+		VerificationURI         string `json:"verification_uri"`
+		VerificationURIComplete string `json:"verification_uri_complete"`
+		ExpiresIn               int    `json:"expires_in"`
+		Interval                int    `json:"interval"`
+	}
+	if err := json.Unmarshal(b, &dr); err != nil {
+		return nil, fmt.Errorf("failed to parse the device authorization response: %w", err)
+	}
+	if dr.DeviceCode == "" || dr.UserCode == "" || dr.VerificationURI == "" {
+		return nil, errors.New("device authorization endpoint returned no device code, user code or verification URI")
+	}
+	a := &DeviceAuthorization{
+		UserCode:                dr.UserCode,
+		VerificationURI:         dr.VerificationURI,
+		VerificationURIComplete: dr.VerificationURIComplete,
+		deviceCode:              dr.DeviceCode,
+		expiresIn:               dr.ExpiresIn,
+		interval:                dr.Interval,
+	}
+	if a.expiresIn <= 0 {
+		a.expiresIn = defaultDeviceExpiresIn
+	}
+	if a.interval <= 0 {
+		a.interval = defaultDeviceInterval
+	}
+	return a, nil
+}
+
+// pollDeviceToken polls the token endpoint until the user approves or denies the authorization, or the code expires.
+func pollDeviceToken(ctx context.Context, tokenURL, clientID string, a *DeviceAuthorization) (*tokenResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(a.expiresIn)*deviceIntervalUnit)
+	defer cancel()
+	interval := time.Duration(a.interval) * deviceIntervalUnit
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, errors.New("the code has expired before the login was approved")
+			}
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+		res, err := requestToken(ctx, tokenURL, url.Values{
+			"grant_type":  {deviceCodeGrantType},
+			"device_code": {a.deviceCode},
+			"client_id":   {clientID},
+		})
+		var oe *oauthError
+		switch {
+		case err == nil:
+			return res, nil
+		case errors.As(err, &oe) && oe.Code == "authorization_pending":
+		case errors.As(err, &oe) && oe.Code == "slow_down":
+			interval += defaultDeviceInterval * deviceIntervalUnit
+		case errors.As(err, &oe) && oe.Code == "access_denied":
+			return nil, errors.New("the login was denied")
+		case errors.As(err, &oe) && oe.Code == "expired_token":
+			return nil, errors.New("the code has expired before the login was approved")
+		default:
+			return nil, err
+		}
+	}
+}
+
+// credentialsPath returns the path of the file of the credentials saved by Login.
+func credentialsPath(cfg config.Auth) (string, error) {
+	if cfg.CredentialsFile != "" {
+		return cfg.CredentialsFile, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine the credentials file, set auth.credentialsFile: %w", err)
+	}
+	return filepath.Join(dir, version.Name, "credentials.json"), nil
+}
+
+// loadCredentials loads the credentials by base URL of the platform. A missing file has no credentials.
+func loadCredentials(path string) (map[string]*loginCredential, error) {
+	creds := map[string]*loginCredential{}
+	b, err := os.ReadFile(path) //nolint:gosec
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return creds, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return creds, nil
+}
+
+// saveCredential saves the credential of the platform, keeping the credentials of the other platforms.
+func saveCredential(path, baseURL string, cred *loginCredential) error {
+	creds, err := loadCredentials(path)
+	if err != nil {
+		return err
+	}
+	creds[baseURL] = cred
+	b, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0600)
+}
+
+// loginSource reads the credential of the platform saved by Login, refreshing the token with the refresh token
+// when it expires or is rejected and saving the refreshed credential, as the refresh token may be rotated.
+// It returns nil when there is no credential of the platform.
+func loginSource(path, baseURL, tokenURL string) (*tokenSource, error) {
+	creds, err := loadCredentials(path)
+	if err != nil {
+		return nil, err
+	}
+	cred := creds[baseURL]
+	if cred == nil || cred.AccessToken == "" {
+		return nil, nil
+	}
+	fetched := false
+	return &tokenSource{
+		name: path,
+		fetch: func(ctx context.Context, renew bool) (string, time.Time, error) {
+			if !fetched && !renew && (cred.Expiry.IsZero() || time.Until(cred.Expiry) > tokenExpiryDelta) {
+				fetched = true
+				return cred.AccessToken, cred.Expiry, nil
+			}
+			if cred.RefreshToken == "" {
+				return "", time.Time{}, errors.New("the token has expired, log in again with `patterner login`")
+			}
+			form := url.Values{
+				"grant_type":    {"refresh_token"},
+				"refresh_token": {cred.RefreshToken},
+			}
+			if cred.ClientID != "" {
+				form.Set("client_id", cred.ClientID)
+			}
+			res, err := requestToken(ctx, tokenURL, form)
+			if err != nil {
+				return "", time.Time{}, fmt.Errorf("failed to refresh the token, log in again with `patterner login`: %w", err)
+			}
+			refreshed := &loginCredential{AccessToken: res.AccessToken, RefreshToken: cred.RefreshToken, Expiry: res.expiry(), ClientID: cred.ClientID}
+			if res.RefreshToken != "" {
+				refreshed.RefreshToken = res.RefreshToken
+			}
+			if err := saveCredential(path, baseURL, refreshed); err != nil {
+				return "", time.Time{}, fmt.Errorf("failed to save the refreshed credential: %w", err)
+			}
+			cred = refreshed
+			fetched = true
+			return cred.AccessToken, cred.Expiry, nil
+		},
+	}, nil
+}
@@ -2,7 +2,10 @@ package tailor
 
 import (
 	"errors"
+	"fmt"
 	"math"
+	"slices"
+	"strings"
 
 	tailorv1 "buf.build/gen/go/tailor-inc/tailor/protocolbuffers/go/tailor/v1"
 )
@@ -57,6 +60,13 @@ func (c *Client) Metrics(resources *Resources) ([]Metric, error) {
 		Unit:  "",
 	})
 
+	metrics = append(metrics, Metric{
+		Key:   "resource_fetch_errors_total",
+		Name:  "Total number of resources that could not be fetched",
+		Value: float64(len(resources.FetchErrors)),
+		Unit:  "",
+	})
+
 	// Pipeline Metrics
 	metrics = append(metrics, Metric{
 		Key:   "pipelines_total",
@@ -65,27 +75,11 @@ func (c *Client) Metrics(resources *Resources) ([]Metric, error) {
 		Unit:  "",
 	})
 	resolversTotal := 0
-	stepsTotal := 0
-	graphQLStepsTotal := 0
-	functionStepsTotal := 0
-	executionPathsTotal := 0
+	var stats pipelineStats
 	for _, p := range resources.Pipelines {
 		resolversTotal += len(p.Resolvers)
 		for _, r := range p.Resolvers {
-			testsCount := 0
-			stepsTotal += len(r.Steps)
-			for _, s := range r.Steps {
-				if s.Operation.Test != "" {
-					testsCount++
-				}
-				switch s.Operation.Type {
-				case tailorv1.PipelineResolver_OPERATION_TYPE_GRAPHQL:
-					graphQLStepsTotal++
-				case tailorv1.PipelineResolver_OPERATION_TYPE_FUNCTION:
-					functionStepsTotal++
-				}
-			}
-			executionPathsTotal += len(r.Steps) * int(math.Pow(2, float64(testsCount)))
+			stats.add(r)
 		}
 	}
 	metrics = append(metrics, Metric{
@@ -94,36 +88,33 @@ func (c *Client) Metrics(resources *Resources) ([]Metric, error) {
 		Value: float64(resolversTotal),
 		Unit:  "",
 	})
+	metrics = append(metrics, stats.metrics()...)
+	complexities, err := c.Complexity(resources)
+	if err != nil {
+		return nil, err
+	}
+	var complexityMax, complexityTotal int
+	for _, rc := range complexities {
+		complexityMax = max(complexityMax, rc.Score())
+		complexityTotal += rc.Score()
+	}
+	var complexityAverage float64
+	if len(complexities) > 0 {
+		complexityAverage = float64(complexityTotal) / float64(len(complexities))
+	}
 	metrics = append(metrics, Metric{
-		Key:   "pipeline_resolver_steps_total",
-		Name:  "Total number of Pipeline resolver steps",
-		Value: float64(stepsTotal),
-		Unit:  "",
-	})
-	metrics = append(metrics, Metric{
-		Key:   "pipeline_resolver_graphql_steps_total",
-		Name:  "Total number of Pipeline resolver GraphQL steps",
-		Value: float64(graphQLStepsTotal),
+		Key:   "pipeline_resolver_complexity_max",
+		Name:  "Maximum Pipeline resolver complexity",
+		Value: float64(complexityMax),
 		Unit:  "",
 	})
 	metrics = append(metrics, Metric{
-		Key:   "pipeline_resolver_function_steps_total",
-		Name:  "Total number of Pipeline resolver Function steps",
-		Value: float64(functionStepsTotal),
+		Key:   "pipeline_resolver_complexity_average",
+		Name:  "Average Pipeline resolver complexity",
+		Value: complexityAverage,
 		Unit:  "",
 	})
 
-	pathsMetic := Metric{
-		Key:   "pipeline_resolver_execution_paths_total",
-		Name:  "Total number of Pipeline resolver execution paths",
-		Value: float64(executionPathsTotal),
-		Unit:  "",
-	}
-	if executionPathsTotal < 0 {
-		pathsMetic.Error = errors.New("overflow detected")
-	}
-	metrics = append(metrics, pathsMetic)
-
 	// TailorDB Metrics
 	metrics = append(metrics, Metric{
 		Key:   "tailordbs_total",
@@ -162,3 +153,208 @@ func (c *Client) Metrics(resources *Resources) ([]Metric, error) {
 
 	return metrics, nil
 }
+
+type MetricDimension string
+
+const (
+	MetricDimensionPipelineNamespace MetricDimension = "pipeline_namespace"
+	MetricDimensionTailorDBNamespace MetricDimension = "tailordb_namespace"
+	MetricDimensionResolver          MetricDimension = "resolver"
+)
+
+// MetricGroup is a set of metrics of a pipeline namespace, a TailorDB namespace or a resolver.
+type MetricGroup struct {
+	Dimension MetricDimension
+	// Namespace is the pipeline or TailorDB namespace name.
+	Namespace string
+	// Resolver is the resolver name. It is empty unless Dimension is MetricDimensionResolver.
+	Resolver string
+	Metrics  []Metric
+}
+
+// Name returns the name of the group ("<namespace>" or "<namespace>/<resolver>").
+func (g *MetricGroup) Name() string {
+	if g.Resolver == "" {
+		return g.Namespace
+	}
+	return g.Namespace + "/" + g.Resolver
+}
+
+// MetricsBy returns the metrics broken down by the dimension.
+func (c *Client) MetricsBy(resources *Resources, dimension MetricDimension) ([]*MetricGroup, error) {
+	coverage, err := c.Coverage(resources)
+	if err != nil {
+		return nil, err
+	}
+	warns, err := c.Lint(resources)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []*MetricGroup
+	switch dimension {
+	case MetricDimensionPipelineNamespace:
+		for _, p := range resources.Pipelines {
+			g := &MetricGroup{
+				Dimension: dimension,
+				Namespace: p.NamespaceName,
+			}
+			var stats pipelineStats
+			for _, r := range p.Resolvers {
+				stats.add(r)
+			}
+			var total, covered int
+			for _, rc := range coverage {
+				if rc.PipelineNamespaceName == p.NamespaceName {
+					total += rc.TotalSteps
+					covered += rc.CoveredSteps
+				}
+			}
+			g.Metrics = append(g.Metrics, Metric{
+				Key:   "pipeline_resolvers_total",
+				Name:  "Total number of Pipeline resolvers",
+				Value: float64(len(p.Resolvers)),
+			})
+			g.Metrics = append(g.Metrics, stats.metrics()...)
+			g.Metrics = append(g.Metrics, coverageMetric(covered, total))
+			g.Metrics = append(g.Metrics, lintWarningsMetric(warns, LintTargetTypePipeline, p.NamespaceName, ""))
+			groups = append(groups, g)
+		}
+	case MetricDimensionResolver:
+		for _, p := range resources.Pipelines {
+			for _, r := range p.Resolvers {
+				g := &MetricGroup{
+					Dimension: dimension,
+					Namespace: p.NamespaceName,
+					Resolver:  r.Name,
+				}
+				var stats pipelineStats
+				stats.add(r)
+				var total, covered int
+				for _, rc := range coverage {
+					if rc.PipelineNamespaceName == p.NamespaceName && rc.Name == r.Name {
+						total += rc.TotalSteps
+						covered += rc.CoveredSteps
+					}
+				}
+				g.Metrics = append(g.Metrics, stats.metrics()...)
+				g.Metrics = append(g.Metrics, coverageMetric(covered, total))
+				g.Metrics = append(g.Metrics, lintWarningsMetric(warns, LintTargetTypePipeline, p.NamespaceName, r.Name))
+				groups = append(groups, g)
+			}
+		}
+	case MetricDimensionTailorDBNamespace:
+		for _, db := range resources.TailorDBs {
+			g := &MetricGroup{
+				Dimension: dimension,
+				Namespace: db.NamespaceName,
+			}
+			fieldsTotal := 0
+			for _, t := range db.Types {
+				fieldsTotal += len(t.Fields)
+			}
+			g.Metrics = append(g.Metrics, Metric{
+				Key:   "tailordb_types_total",
+				Name:  "Total number of TailorDB types",
+				Value: float64(len(db.Types)),
+			})
+			g.Metrics = append(g.Metrics, Metric{
+				Key:   "tailordb_type_fields_total",
+				Name:  "Total number of TailorDB type fields",
+				Value: float64(fieldsTotal),
+			})
+			g.Metrics = append(g.Metrics, lintWarningsMetric(warns, LintTargetTypeTailorDB, db.NamespaceName, ""))
+			groups = append(groups, g)
+		}
+	default:
+		return nil, fmt.Errorf("unknown metric dimension: %s", dimension)
+	}
+
+	slices.SortStableFunc(groups, func(a, b *MetricGroup) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return groups, nil
+}
+
+// pipelineStats accumulates the step counts of pipeline resolvers.
+type pipelineStats struct {
+	steps          int
+	graphQLSteps   int
+	functionSteps  int
+	executionPaths int
+}
+
+func (s *pipelineStats) add(r *PipelineResolver) {
+	testsCount := 0
+	s.steps += len(r.Steps)
+	for _, step := range r.Steps {
+		if step.Operation.Test != "" {
+			testsCount++
+		}
+		switch step.Operation.Type {
+		case tailorv1.PipelineResolver_OPERATION_TYPE_GRAPHQL:
+			s.graphQLSteps++
+		case tailorv1.PipelineResolver_OPERATION_TYPE_FUNCTION:
+			s.functionSteps++
+		}
+	}
+	s.executionPaths += len(r.Steps) * int(math.Pow(2, float64(testsCount)))
+}
+
+func (s *pipelineStats) metrics() []Metric {
+	paths := Metric{
+		Key:   "pipeline_resolver_execution_paths_total",
+		Name:  "Total number of Pipeline resolver execution paths",
+		Value: float64(s.executionPaths),
+	}
+	if s.executionPaths < 0 {
+		paths.Error = errors.New("overflow detected")
+	}
+	return []Metric{
+		{
+			Key:   "pipeline_resolver_steps_total",
+			Name:  "Total number of Pipeline resolver steps",
+			Value: float64(s.steps),
+		},
+		{
+			Key:   "pipeline_resolver_graphql_steps_total",
+			Name:  "Total number of Pipeline resolver GraphQL steps",
+			Value: float64(s.graphQLSteps),
+		},
+		{
+			Key:   "pipeline_resolver_function_steps_total",
+			Name:  "Total number of Pipeline resolver Function steps",
+			Value: float64(s.functionSteps),
+		},
+		paths,
+	}
+}
+
+func coverageMetric(covered, total int) Metric {
+	return Metric{
+		Key:   "pipeline_resolver_step_coverage_percentage",
+		Name:  "Pipeline resolver step coverage",
+		Value: coveragePercentage(covered, total),
+		Unit:  "%",
+	}
+}
+
+// lintWarningsMetric counts the warnings of the namespace, and of the resource if it is not empty.
+func lintWarningsMetric(warns []*LintWarn, typ LintTargetType, namespace, resource string) Metric {
+	var count int
+	for _, w := range warns {
+		if w.Type != typ || w.Namespace != namespace {
+			continue
+		}
+		if resource != "" && w.Resource != resource {
+			continue
+		}
+		count++
+	}
+	return Metric{
+		Key:   "lint_warnings_total",
+		Name:  "Total number of lint warnings",
+		Value: float64(count),
+	}
+}
@@ -23,12 +23,15 @@ func TestClient_Metrics(t *testing.T) {
 			expectedMetrics: map[string]float64{
 				"pipeline_resolver_step_coverage_percentage": 0,
 				"lint_warnings_total":                        0,
+				"resource_fetch_errors_total":                0,
 				"pipelines_total":                            0,
 				"pipeline_resolvers_total":                   0,
 				"pipeline_resolver_steps_total":              0,
 				"pipeline_resolver_graphql_steps_total":      0,
 				"pipeline_resolver_function_steps_total":     0,
 				"pipeline_resolver_execution_paths_total":    0, // 0 resolvers = 0 paths
+				"pipeline_resolver_complexity_max":           0,
+				"pipeline_resolver_complexity_average":       0,
 				"tailordbs_total":                            0,
 				"tailordb_types_total":                       0,
 				"tailordb_type_fields_total":                 0,
@@ -71,12 +74,15 @@ func TestClient_Metrics(t *testing.T) {
 			expectedMetrics: map[string]float64{
 				"pipeline_resolver_step_coverage_percentage": 0, // no coverage data for test
 				"lint_warnings_total":                        1,
+				"resource_fetch_errors_total":                0,
 				"pipelines_total":                            1,
 				"pipeline_resolvers_total":                   1,
 				"pipeline_resolver_steps_total":              1,
 				"pipeline_resolver_graphql_steps_total":      0,
 				"pipeline_resolver_function_steps_total":     0,
 				"pipeline_resolver_execution_paths_total":    1, // 1 * 2^0 = 1 (1 step, no tests)
+				"pipeline_resolver_complexity_max":           1,
+				"pipeline_resolver_complexity_average":       1,
 				"tailordbs_total":                            1,
 				"tailordb_types_total":                       1,
 				"tailordb_type_fields_total":                 2, // id and name fields
@@ -162,12 +168,15 @@ func TestClient_Metrics(t *testing.T) {
 			expectedMetrics: map[string]float64{
 				"pipeline_resolver_step_coverage_percentage": 0, // no coverage data for test
 				"lint_warnings_total":                        3,
+				"resource_fetch_errors_total":                0,
 				"pipelines_total":                            2, // ns1, ns2
 				"pipeline_resolvers_total":                   3, // resolver1, resolver2, resolver3
 				"pipeline_resolver_steps_total":              6, // 2+3+1 steps
 				"pipeline_resolver_graphql_steps_total":      0,
 				"pipeline_resolver_function_steps_total":     0,
 				"pipeline_resolver_execution_paths_total":    6, // 2*2^0 + 3*2^0 + 1*2^0 = 2+3+1 (no tests)
+				"pipeline_resolver_complexity_max":           3,
+				"pipeline_resolver_complexity_average":       2,
 				"tailordbs_total":                            2, // two TailorDB instances
 				"tailordb_types_total":                       3, // User, Post, Comment
 				"tailordb_type_fields_total":                 9, // 3+2+4 fields
@@ -208,12 +217,15 @@ func TestClient_Metrics(t *testing.T) {
 			expectedMetrics: map[string]float64{
 				"pipeline_resolver_step_coverage_percentage": 0,
 				"lint_warnings_total":                        0,
+				"resource_fetch_errors_total":                0,
 				"pipelines_total":                            0,
 				"pipeline_resolvers_total":                   0,
 				"pipeline_resolver_steps_total":              0,
 				"pipeline_resolver_graphql_steps_total":      0,
 				"pipeline_resolver_function_steps_total":     0,
 				"pipeline_resolver_execution_paths_total":    0, // 0 resolvers = 0 paths
+				"pipeline_resolver_complexity_max":           0,
+				"pipeline_resolver_complexity_average":       0,
 				"tailordbs_total":                            1,
 				"tailordb_types_total":                       1,
 				"tailordb_type_fields_total":                 2, // Only top-level fields are counted
@@ -596,12 +608,15 @@ func TestClient_Metrics_MetricKeys(t *testing.T) {
 	expectedMetricKeys := []string{
 		"pipeline_resolver_step_coverage_percentage",
 		"lint_warnings_total",
+		"resource_fetch_errors_total",
 		"pipelines_total",
 		"pipeline_resolvers_total",
 		"pipeline_resolver_steps_total",
 		"pipeline_resolver_graphql_steps_total",
 		"pipeline_resolver_function_steps_total",
 		"pipeline_resolver_execution_paths_total",
+		"pipeline_resolver_complexity_max",
+		"pipeline_resolver_complexity_average",
 		"tailordbs_total",
 		"tailordb_types_total",
 		"tailordb_type_fields_total",
@@ -1295,3 +1310,145 @@ func TestClient_Metrics_StepTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_MetricsBy(t *testing.T) {
+	cfg := createTestConfig(t)
+	client, err := New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	resources := &Resources{
+		Pipelines: []*Pipeline{
+			{
+				NamespaceName: "ns2",
+				Resolvers: []*PipelineResolver{
+					{
+						Name: "resolver3",
+						Steps: []*PipelineStep{
+							{Name: "step1", Operation: PipelineStepOperation{Type: tailorv1.PipelineResolver_OPERATION_TYPE_FUNCTION}},
+						},
+					},
+				},
+			},
+			{
+				NamespaceName: "ns1",
+				Resolvers: []*PipelineResolver{
+					{
+						Name:          "resolver1",
+						Authorization: "true",
+						Steps: []*PipelineStep{
+							{Name: "step1", Operation: PipelineStepOperation{Type: tailorv1.PipelineResolver_OPERATION_TYPE_FUNCTION, Test: "true"}},
+							{Name: "step2", Operation: PipelineStepOperation{Type: tailorv1.PipelineResolver_OPERATION_TYPE_FUNCTION}},
+						},
+						ExecutionResults: []*tailorv1.PipelineResolverExecutionResult{
+							{LastPipelineName: "step1"},
+						},
+					},
+					{
+						Name: "resolver2",
+						Steps: []*PipelineStep{
+							{Name: "step1", Operation: PipelineStepOperation{Type: tailorv1.PipelineResolver_OPERATION_TYPE_FUNCTION}},
+						},
+					},
+				},
+			},
+		},
+		TailorDBs: []*TailorDB{
+			{
+				NamespaceName: "db1",
+				Types: []*TailorDBType{
+					{Name: "User", Draft: true, Fields: []*TailorDBField{{Name: "name"}, {Name: "email"}}},
+					{Name: "Role", Fields: []*TailorDBField{{Name: "name"}}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		dimension MetricDimension
+		want      map[string]map[string]float64
+	}{
+		{
+			dimension: MetricDimensionPipelineNamespace,
+			want: map[string]map[string]float64{
+				"ns1": {
+					"pipeline_resolvers_total":                   2,
+					"pipeline_resolver_steps_total":              3,
+					"pipeline_resolver_function_steps_total":     3,
+					"pipeline_resolver_execution_paths_total":    5, // 2*2^1 + 1*2^0
+					"pipeline_resolver_step_coverage_percentage": float64(1) / float64(3) * 100,
+					"lint_warnings_total":                        1,
+				},
+				"ns2": {
+					"pipeline_resolvers_total": 1,
+					"lint_warnings_total":      0,
+				},
+			},
+		},
+		{
+			dimension: MetricDimensionResolver,
+			want: map[string]map[string]float64{
+				"ns1/resolver1": {
+					"pipeline_resolver_steps_total":              2,
+					"pipeline_resolver_step_coverage_percentage": 50,
+					"lint_warnings_total":                        1,
+				},
+				"ns1/resolver2": {
+					"pipeline_resolver_steps_total": 1,
+					"lint_warnings_total":           0,
+				},
+				"ns2/resolver3": {
+					"pipeline_resolver_steps_total": 1,
+				},
+			},
+		},
+		{
+			dimension: MetricDimensionTailorDBNamespace,
+			want: map[string]map[string]float64{
+				"db1": {
+					"tailordb_types_total":       2,
+					"tailordb_type_fields_total": 3,
+					"lint_warnings_total":        1,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.dimension), func(t *testing.T) {
+			groups, err := client.MetricsBy(resources, tt.dimension)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(groups) != len(tt.want) {
+				t.Fatalf("Expected %d groups, got %d", len(tt.want), len(groups))
+			}
+			for i := 1; i < len(groups); i++ {
+				if groups[i-1].Name() > groups[i].Name() {
+					t.Errorf("Groups are not sorted: %s > %s", groups[i-1].Name(), groups[i].Name())
+				}
+			}
+			for _, g := range groups {
+				want, ok := tt.want[g.Name()]
+				if !ok {
+					t.Errorf("Unexpected group %s", g.Name())
+					continue
+				}
+				got := map[string]float64{}
+				for _, m := range g.Metrics {
+					got[m.Key] = m.Value
+				}
+				for key, v := range want {
+					if got[key] != v {
+						t.Errorf("%s %s = %v, want %v", g.Name(), key, got[key], v)
+					}
+				}
+			}
+		})
+	}
+
+	if _, err := client.MetricsBy(resources, MetricDimension("unknown")); err == nil {
+		t.Error("Expected error for unknown dimension")
+	}
+}
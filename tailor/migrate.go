@@ -0,0 +1,294 @@
+package tailor
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	tailorv1 "buf.build/gen/go/tailor-inc/tailor/protocolbuffers/go/tailor/v1"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// CELMigration is a CEL expression of a resource and its translation into a JavaScript hook.
+type CELMigration struct {
+	Type LintTargetType
+	Name string
+	// Namespace and Resource identify the resolver or type the expression belongs to.
+	Namespace string
+	Resource  string
+	// Field is the deprecated field (e.g., pre_script).
+	Field string
+	// Replacement is the hook replacing it (e.g., pre_hook).
+	Replacement string
+	CEL         string
+	*CELTranslation
+}
+
+// MigrateCEL translates the CEL scripts of the pipeline resolvers and steps and the CEL hooks of the TailorDB fields into JavaScript hooks.
+func MigrateCEL(resources *Resources) []*CELMigration {
+	var migrations []*CELMigration
+	for _, db := range resources.TailorDBs {
+		for _, t := range db.Types {
+			walkFields(t.Fields, "", func(name string, f *TailorDBField) {
+				for _, e := range []struct{ field, replacement, expr string }{
+					{"create_expr", "create", f.Hooks.CreateExpr},
+					{"update_expr", "update", f.Hooks.UpdateExpr},
+				} {
+					if e.expr == "" {
+						continue
+					}
+					migrations = append(migrations, &CELMigration{
+						Type:           LintTargetTypeTailorDB,
+						Name:           fmt.Sprintf("%s/%s field %s", db.NamespaceName, t.Name, name),
+						Namespace:      db.NamespaceName,
+						Resource:       t.Name,
+						Field:          e.field,
+						Replacement:    e.replacement,
+						CEL:            e.expr,
+						CELTranslation: TranslateCEL(e.expr),
+					})
+				}
+			})
+		}
+	}
+	for _, p := range resources.Pipelines {
+		for _, r := range p.Resolvers {
+			// script is the field of the step also becoming the replacement of a validation, if any.
+			add := func(name, field, replacement, expr, script string) {
+				if expr == "" {
+					return
+				}
+				m := &CELMigration{
+					Type:           LintTargetTypePipeline,
+					Name:           name,
+					Namespace:      p.NamespaceName,
+					Resource:       r.Name,
+					Field:          field,
+					Replacement:    replacement,
+					CEL:            expr,
+					CELTranslation: TranslateCEL(expr),
+				}
+				if (field == "pre_validation" || field == "post_validation") && m.Err == nil {
+					var note string
+					m.JS, note = validationHook(m.JS, field == "post_validation")
+					m.Notes = append(m.Notes, note)
+					if script != "" {
+						m.Notes = append(m.Notes, fmt.Sprintf("the step also has `%s`, which becomes `%s` too; merge the hooks into one", script, replacement))
+					}
+				}
+				migrations = append(migrations, m)
+			}
+			name := fmt.Sprintf("%s/%s", p.NamespaceName, r.Name)
+			add(name, "pre_script", "pre_hook", r.PreScript, "")
+			add(name, "post_script", "post_hook", r.PostScript, "")
+			for _, s := range r.Steps {
+				name := fmt.Sprintf("%s/%s step %s", p.NamespaceName, r.Name, s.Name)
+				var preScript, postScript string
+				if s.PreScript != "" {
+					preScript = "pre_script"
+				}
+				if s.PostScript != "" {
+					postScript = "post_script"
+				}
+				add(name, "pre_validation", "pre_hook", s.PreValidation, preScript)
+				add(name, "pre_script", "pre_hook", s.PreScript, "")
+				add(name, "post_script", "post_hook", s.PostScript, "")
+				add(name, "post_validation", "post_hook", s.PostValidation, postScript)
+			}
+		}
+	}
+	return migrations
+}
+
+// walkFields calls fn for the fields and their nested fields, named by their dotted paths.
+func walkFields(fields []*TailorDBField, prefix string, fn func(name string, f *TailorDBField)) {
+	for _, f := range fields {
+		name := prefix + f.Name
+		fn(name, f)
+		walkFields(f.Fields, name+".", fn)
+	}
+}
+
+// DraftMigration is the plan to migrate from the draft feature of TailorDB.
+type DraftMigration struct {
+	Types     []*DraftType
+	Resolvers []*DraftResolver
+}
+
+// DraftType is a draft-enabled type.
+type DraftType struct {
+	Namespace string
+	Name      string
+	// Usages is the number of calls to its draft mutations in the pipeline steps.
+	Usages int
+}
+
+// DraftResolver is a pipeline resolver whose steps use draft mutations.
+type DraftResolver struct {
+	Namespace string
+	Name      string
+	Usages    []*DraftUsage
+}
+
+// DraftUsage is a draft mutation called by a pipeline step.
+type DraftUsage struct {
+	Step     string
+	Mutation string
+	// Type is the type the mutation drafts.
+	Type string
+	// Replacement is the operation suggested to replace the mutation.
+	Replacement string
+	// Suggestion describes how the replacement takes over the mutation.
+	Suggestion string
+}
+
+// draftReplacements are the operations suggested to replace the draft mutations, by the prefixes of the mutations.
+var draftReplacements = map[string]struct{ prefix, suggestion string }{
+	"appendDraft":  {"create", "create the record with a status field marking it as a draft"},
+	"confirmDraft": {"update", "update the status field of the record to confirm it"},
+	"cancelDraft":  {"delete", "delete the record, or update its status field to cancel it"},
+}
+
+// MigrateDraft lists the draft-enabled types and the pipeline steps calling their draft mutations, grouped by resolver.
+// The steps calling the draft mutations of the types filtered out are listed too, as only the names of those types are known.
+func MigrateDraft(resources *Resources) (*DraftMigration, error) {
+	m := &DraftMigration{}
+	typeNames := resources.tailorDBTypeNames()
+	for _, db := range resources.TailorDBs {
+		for _, t := range db.Types {
+			if t.Draft {
+				m.Types = append(m.Types, &DraftType{Namespace: db.NamespaceName, Name: t.Name})
+			}
+		}
+	}
+	for _, p := range resources.Pipelines {
+		for _, r := range p.Resolvers {
+			dr := &DraftResolver{Namespace: p.NamespaceName, Name: r.Name}
+			for _, s := range r.Steps {
+				fields, err := operationFields(p.NamespaceName, r.Name, s)
+				if err != nil {
+					return nil, err
+				}
+				for _, f := range fields {
+					prefix := draftMutationPrefixRe.FindString(f)
+					typeName := f[len(prefix):]
+					if prefix == "" || !slices.Contains(typeNames, typeName) {
+						continue
+					}
+					replacement := draftReplacements[prefix]
+					dr.Usages = append(dr.Usages, &DraftUsage{
+						Step:        s.Name,
+						Mutation:    f,
+						Type:        typeName,
+						Replacement: replacement.prefix + typeName,
+						Suggestion:  replacement.suggestion,
+					})
+					for _, t := range m.Types {
+						if t.Name == typeName {
+							t.Usages++
+						}
+					}
+				}
+			}
+			if len(dr.Usages) > 0 {
+				m.Resolvers = append(m.Resolvers, dr)
+			}
+		}
+	}
+	return m, nil
+}
+
+// operationFields returns the names of the top-level fields of the GraphQL operation of the step.
+func operationFields(namespace, resolver string, s *PipelineStep) ([]string, error) {
+	if s.Operation.Type != tailorv1.PipelineResolver_OPERATION_TYPE_GRAPHQL {
+		return nil, nil
+	}
+	query, err := parser.ParseQuery(&ast.Source{
+		Input: s.Operation.Source,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GraphQL operation in %s/%s step %s: %w", namespace, resolver, s.Name, err)
+	}
+	var fields []string
+	for _, op := range query.Operations {
+		for _, selection := range op.SelectionSet {
+			if f, ok := selection.(*ast.Field); ok {
+				fields = append(fields, f.Name)
+			}
+		}
+	}
+	return fields, nil
+}
+
+// StateFlowMigration is the impact of removing StateFlow.
+type StateFlowMigration struct {
+	Namespaces []*StateFlowNamespace
+	// Usages are the StateFlow mutations called by the pipeline steps, the most executed first.
+	Usages []*StateFlowUsage
+}
+
+// StateFlowNamespace is a StateFlow namespace and the IDs of its admin users.
+type StateFlowNamespace struct {
+	Name       string
+	AdminUsers []string
+}
+
+// StateFlowUsage is a StateFlow mutation called by a pipeline step.
+type StateFlowUsage struct {
+	Namespace string
+	Resolver  string
+	Step      string
+	Mutation  string
+	// Executions and FailedExecutions are the numbers of the executions of the step in the execution results.
+	Executions       int
+	FailedExecutions int
+}
+
+// MigrateStateFlow lists the StateFlow namespaces with their admin users and the pipeline steps calling newState or moveState
+// with their numbers of executions, to plan the removal of StateFlow.
+func MigrateStateFlow(resources *Resources) (*StateFlowMigration, error) {
+	m := &StateFlowMigration{}
+	for _, sf := range resources.StateFlows {
+		ns := &StateFlowNamespace{Name: sf.NamespaceName}
+		for _, u := range sf.AdminUsers {
+			ns.AdminUsers = append(ns.AdminUsers, u.UserID)
+		}
+		m.Namespaces = append(m.Namespaces, ns)
+	}
+	coverages, err := coverage(resources)
+	if err != nil {
+		return nil, err
+	}
+	// The coverages are in the order of the resolvers.
+	i := 0
+	for _, p := range resources.Pipelines {
+		for _, r := range p.Resolvers {
+			for j, s := range r.Steps {
+				fields, err := operationFields(p.NamespaceName, r.Name, s)
+				if err != nil {
+					return nil, err
+				}
+				for _, f := range fields {
+					if !slices.Contains(stateFlowMutations, f) {
+						continue
+					}
+					sc := coverages[i].Steps[j]
+					m.Usages = append(m.Usages, &StateFlowUsage{
+						Namespace:        p.NamespaceName,
+						Resolver:         r.Name,
+						Step:             s.Name,
+						Mutation:         f,
+						Executions:       sc.Count,
+						FailedExecutions: sc.FailedCount,
+					})
+				}
+			}
+			i++
+		}
+	}
+	slices.SortStableFunc(m.Usages, func(a, b *StateFlowUsage) int {
+		return cmp.Compare(b.Executions, a.Executions)
+	})
+	return m, nil
+}
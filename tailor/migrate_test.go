@@ -0,0 +1,189 @@
+package tailor
+
+import (
+	"reflect"
+	"testing"
+
+	tailorv1 "buf.build/gen/go/tailor-inc/tailor/protocolbuffers/go/tailor/v1"
+)
+
+func TestMigrateCEL(t *testing.T) {
+	resources := &Resources{
+		TailorDBs: []*TailorDB{
+			{
+				NamespaceName: "db",
+				Types: []*TailorDBType{
+					{
+						Name: "Order",
+						Fields: []*TailorDBField{
+							{Name: "status", Hooks: TailorDBFieldHooks{CreateExpr: "'draft'"}},
+							{Name: "address", Fields: []*TailorDBField{
+								{Name: "zip", Hooks: TailorDBFieldHooks{UpdateExpr: "duration(_value)"}},
+							}},
+						},
+					},
+				},
+			},
+		},
+		Pipelines: []*Pipeline{
+			{
+				NamespaceName: "pipeline",
+				Resolvers: []*PipelineResolver{
+					{
+						Name: "createOrder",
+						Steps: []*PipelineStep{
+							{Name: "create", PreScript: "{'input': context.args}", PreValidation: "context.args.amount > 0"},
+						},
+					},
+				},
+			},
+		},
+	}
+	got := MigrateCEL(resources)
+	want := []struct {
+		name, field, js string
+		manual          bool
+	}{
+		{"db/Order field status", "create_expr", `"draft"`, false},
+		{"db/Order field address.zip", "update_expr", "", true},
+		{"pipeline/createOrder step create", "pre_validation", `(() => { if (!(context.args.amount > 0)) throw new Error("validation failed"); return context.args })()`, true},
+		{"pipeline/createOrder step create", "pre_script", `({"input": context.args})`, false},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("MigrateCEL() returned %d migrations, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i].Name != w.name || got[i].Field != w.field || got[i].JS != w.js || got[i].NeedsManualWork() != w.manual {
+			t.Errorf("MigrateCEL()[%d] = %+v %+v, want %+v", i, got[i], got[i].CELTranslation, w)
+		}
+	}
+}
+
+func TestMigrateDraft(t *testing.T) {
+	gql := func(source string) PipelineStepOperation {
+		return PipelineStepOperation{Type: tailorv1.PipelineResolver_OPERATION_TYPE_GRAPHQL, Source: source}
+	}
+	resources := &Resources{
+		TailorDBs: []*TailorDB{
+			{
+				NamespaceName: "db",
+				Types: []*TailorDBType{
+					{Name: "Order", Draft: true},
+					{Name: "Invoice", Draft: true},
+					{Name: "Customer"},
+				},
+			},
+		},
+		// Quote is a draft-enabled type in a namespace filtered out.
+		filteredTypeNames: []string{"Quote"},
+		Pipelines: []*Pipeline{
+			{
+				NamespaceName: "pipeline",
+				Resolvers: []*PipelineResolver{
+					{
+						Name: "placeOrder",
+						Steps: []*PipelineStep{
+							{Name: "draft", Operation: gql(`mutation { appendDraftOrder(input: {}) { id } }`)},
+							{Name: "confirm", Operation: gql(`mutation { confirmDraftOrder(id: "1") { id } cancelDraftOrder(id: "2") { id } }`)},
+						},
+					},
+					{
+						Name: "requestQuote",
+						Steps: []*PipelineStep{
+							{Name: "draft", Operation: gql(`mutation { appendDraftQuote(input: {}) { id } }`)},
+						},
+					},
+					{
+						Name: "getCustomer",
+						Steps: []*PipelineStep{
+							{Name: "get", Operation: gql(`query { customer(id: "1") { id } }`)},
+						},
+					},
+				},
+			},
+		},
+	}
+	got, err := MigrateDraft(resources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &DraftMigration{
+		Types: []*DraftType{
+			{Namespace: "db", Name: "Order", Usages: 3},
+			{Namespace: "db", Name: "Invoice"},
+		},
+		Resolvers: []*DraftResolver{
+			{
+				Namespace: "pipeline",
+				Name:      "placeOrder",
+				Usages: []*DraftUsage{
+					{Step: "draft", Mutation: "appendDraftOrder", Type: "Order", Replacement: "createOrder", Suggestion: draftReplacements["appendDraft"].suggestion},
+					{Step: "confirm", Mutation: "confirmDraftOrder", Type: "Order", Replacement: "updateOrder", Suggestion: draftReplacements["confirmDraft"].suggestion},
+					{Step: "confirm", Mutation: "cancelDraftOrder", Type: "Order", Replacement: "deleteOrder", Suggestion: draftReplacements["cancelDraft"].suggestion},
+				},
+			},
+			{
+				Namespace: "pipeline",
+				Name:      "requestQuote",
+				Usages: []*DraftUsage{
+					{Step: "draft", Mutation: "appendDraftQuote", Type: "Quote", Replacement: "createQuote", Suggestion: draftReplacements["appendDraft"].suggestion},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MigrateDraft() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMigrateStateFlow(t *testing.T) {
+	gql := func(source string) PipelineStepOperation {
+		return PipelineStepOperation{Type: tailorv1.PipelineResolver_OPERATION_TYPE_GRAPHQL, Source: source}
+	}
+	resources := &Resources{
+		StateFlows: []*StateFlow{
+			{NamespaceName: "stateflow", AdminUsers: []*StateFlowAdminUser{{UserID: "user-1"}, {UserID: "user-2"}}},
+		},
+		Pipelines: []*Pipeline{
+			{
+				NamespaceName: "pipeline",
+				Resolvers: []*PipelineResolver{
+					{
+						Name: "startFlow",
+						Steps: []*PipelineStep{
+							{Name: "new", Operation: gql(`mutation { newState(input: {}) { id } }`)},
+						},
+					},
+					{
+						Name: "approve",
+						Steps: []*PipelineStep{
+							{Name: "get", Operation: gql(`query { order(id: "1") { id } }`)},
+							{Name: "move", Operation: gql(`mutation { moveState(input: {}) { id } }`)},
+						},
+						ExecutionResults: []*tailorv1.PipelineResolverExecutionResult{
+							{LastPipelineName: "move"},
+							{LastPipelineName: "move", Status: tailorv1.PipelineResolverExecutionResultStatus_PIPELINE_RESOLVER_EXECUTION_RESULT_STATUS_FAILURE},
+							{LastPipelineName: "get", Status: tailorv1.PipelineResolverExecutionResultStatus_PIPELINE_RESOLVER_EXECUTION_RESULT_STATUS_FAILURE},
+						},
+					},
+				},
+			},
+		},
+	}
+	got, err := MigrateStateFlow(resources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &StateFlowMigration{
+		Namespaces: []*StateFlowNamespace{
+			{Name: "stateflow", AdminUsers: []string{"user-1", "user-2"}},
+		},
+		Usages: []*StateFlowUsage{
+			{Namespace: "pipeline", Resolver: "approve", Step: "move", Mutation: "moveState", Executions: 2, FailedExecutions: 1},
+			{Namespace: "pipeline", Resolver: "startFlow", Step: "new", Mutation: "newState"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MigrateStateFlow() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,164 @@
+package tailor
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	tailorv1 "buf.build/gen/go/tailor-inc/tailor/protocolbuffers/go/tailor/v1"
+)
+
+type ResolverProfile struct {
+	PipelineNamespaceName string
+	Name                  string
+	Calls                 int
+	Errors                int
+	Latency               LatencyDistribution
+	Steps                 []*StepProfile
+}
+
+// StepProfile is the profile of the executions that ended at the step.
+// Execution results do not carry per-step timings, so the latency of an execution is attributed to its last executed step.
+type StepProfile struct {
+	PipelineNamespaceName string
+	ResolverName          string
+	Name                  string
+	Calls                 int
+	Errors                int
+	Latency               LatencyDistribution
+}
+
+type LatencyDistribution struct {
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+	Max time.Duration
+}
+
+// ErrorRate returns the percentage of failed executions.
+func (rp *ResolverProfile) ErrorRate() float64 {
+	return errorRate(rp.Errors, rp.Calls)
+}
+
+// ErrorRate returns the percentage of failed executions.
+func (sp *StepProfile) ErrorRate() float64 {
+	return errorRate(sp.Errors, sp.Calls)
+}
+
+// Profile computes the latency distributions of the pipeline resolvers from the execution results.
+func (c *Client) Profile(resources *Resources) ([]*ResolverProfile, error) {
+	var profiles []*ResolverProfile
+	for _, p := range resources.Pipelines {
+		for _, r := range p.Resolvers {
+			rp := &ResolverProfile{
+				PipelineNamespaceName: p.NamespaceName,
+				Name:                  r.Name,
+			}
+			var latencies []time.Duration
+			stepLatencies := map[string][]time.Duration{}
+			for _, s := range r.Steps {
+				rp.Steps = append(rp.Steps, &StepProfile{
+					PipelineNamespaceName: p.NamespaceName,
+					ResolverName:          r.Name,
+					Name:                  s.Name,
+				})
+			}
+			for _, result := range r.ExecutionResults {
+				failed := executionFailed(result)
+				rp.Calls++
+				if failed {
+					rp.Errors++
+				}
+				latency, ok := executionLatency(result)
+				if ok {
+					latencies = append(latencies, latency)
+				}
+				for _, sp := range rp.Steps {
+					if sp.Name != result.GetLastPipelineName() {
+						continue
+					}
+					sp.Calls++
+					if failed {
+						sp.Errors++
+					}
+					if ok {
+						stepLatencies[sp.Name] = append(stepLatencies[sp.Name], latency)
+					}
+				}
+			}
+			rp.Latency = newLatencyDistribution(latencies)
+			for _, sp := range rp.Steps {
+				sp.Latency = newLatencyDistribution(stepLatencies[sp.Name])
+			}
+			profiles = append(profiles, rp)
+		}
+	}
+
+	return profiles, nil
+}
+
+// SlowestFinalSteps returns the n steps with the highest p95 latency of the executions that ended at them.
+// As the latency of a whole execution is attributed to its last executed step, this ranks the executions by where they stopped,
+// not the steps by their own cost.
+func SlowestFinalSteps(profiles []*ResolverProfile, n int) []*StepProfile {
+	var steps []*StepProfile
+	for _, rp := range profiles {
+		for _, sp := range rp.Steps {
+			if sp.Calls > 0 {
+				steps = append(steps, sp)
+			}
+		}
+	}
+	slices.SortStableFunc(steps, func(a, b *StepProfile) int {
+		if a.Latency.P95 != b.Latency.P95 {
+			return cmp.Compare(b.Latency.P95, a.Latency.P95)
+		}
+		return cmp.Compare(b.Latency.Max, a.Latency.Max)
+	})
+	if n >= 0 && len(steps) > n {
+		steps = steps[:n]
+	}
+	return steps
+}
+
+// executionLatency returns the time between the creation and the last update of the execution result.
+func executionLatency(result *tailorv1.PipelineResolverExecutionResult) (time.Duration, bool) {
+	if result.GetCreatedAt() == nil || result.GetUpdatedAt() == nil {
+		return 0, false
+	}
+	d := result.GetUpdatedAt().AsTime().Sub(result.GetCreatedAt().AsTime())
+	if d < 0 {
+		return 0, false
+	}
+	return d, true
+}
+
+func newLatencyDistribution(latencies []time.Duration) LatencyDistribution {
+	if len(latencies) == 0 {
+		return LatencyDistribution{}
+	}
+	sorted := slices.Clone(latencies)
+	slices.Sort(sorted)
+	return LatencyDistribution{
+		P50: percentile(sorted, 50),
+		P95: percentile(sorted, 95),
+		P99: percentile(sorted, 99),
+		Max: sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile of the sorted latencies.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func errorRate(failed, calls int) float64 {
+	if calls == 0 {
+		return 0
+	}
+	return float64(failed) / float64(calls) * 100
+}
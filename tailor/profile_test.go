@@ -0,0 +1,94 @@
+package tailor
+
+import (
+	"testing"
+	"time"
+
+	tailorv1 "buf.build/gen/go/tailor-inc/tailor/protocolbuffers/go/tailor/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestClient_Profile(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	result := func(lastStep string, latency time.Duration, failed bool) *tailorv1.PipelineResolverExecutionResult {
+		r := &tailorv1.PipelineResolverExecutionResult{
+			LastPipelineName: lastStep,
+			CreatedAt:        timestamppb.New(base),
+			UpdatedAt:        timestamppb.New(base.Add(latency)),
+		}
+		if failed {
+			r.Status = tailorv1.PipelineResolverExecutionResultStatus_PIPELINE_RESOLVER_EXECUTION_RESULT_STATUS_FAILURE
+		}
+		return r
+	}
+	var results []*tailorv1.PipelineResolverExecutionResult
+	for i := 1; i <= 100; i++ {
+		results = append(results, result("step2", time.Duration(i)*time.Millisecond, false))
+	}
+	results = append(results, result("step1", 500*time.Millisecond, true))
+	results = append(results, &tailorv1.PipelineResolverExecutionResult{LastPipelineName: "step1"})
+
+	resources := &Resources{
+		Pipelines: []*Pipeline{
+			{
+				NamespaceName: "test-namespace",
+				Resolvers: []*PipelineResolver{
+					{
+						Name: "test-resolver",
+						Steps: []*PipelineStep{
+							{Name: "step1"},
+							{Name: "step2"},
+						},
+						ExecutionResults: results,
+					},
+					{
+						Name: "idle-resolver",
+						Steps: []*PipelineStep{
+							{Name: "step1"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	c := &Client{}
+	got, err := c.Profile(resources)
+	if err != nil {
+		t.Fatalf("Client.Profile() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Client.Profile() got %d profiles, want 2", len(got))
+	}
+
+	rp := got[0]
+	if rp.Calls != 102 || rp.Errors != 1 {
+		t.Errorf("Calls/Errors = %d/%d, want 102/1", rp.Calls, rp.Errors)
+	}
+	want := LatencyDistribution{
+		P50: 51 * time.Millisecond,
+		P95: 96 * time.Millisecond,
+		P99: 100 * time.Millisecond,
+		Max: 500 * time.Millisecond,
+	}
+	if rp.Latency != want {
+		t.Errorf("Latency = %+v, want %+v", rp.Latency, want)
+	}
+
+	step1 := rp.Steps[0]
+	if step1.Calls != 2 || step1.Errors != 1 || step1.ErrorRate() != 50 {
+		t.Errorf("step1 Calls/Errors/ErrorRate = %d/%d/%.1f, want 2/1/50.0", step1.Calls, step1.Errors, step1.ErrorRate())
+	}
+	if step1.Latency.Max != 500*time.Millisecond {
+		t.Errorf("step1 Latency.Max = %v, want 500ms", step1.Latency.Max)
+	}
+
+	if got[1].Calls != 0 || got[1].Latency != (LatencyDistribution{}) {
+		t.Errorf("idle resolver profile = %+v, want no calls", got[1])
+	}
+
+	slowest := SlowestFinalSteps(got, 1)
+	if len(slowest) != 1 || slowest[0].Name != "step1" {
+		t.Errorf("SlowestFinalSteps() = %v, want [step1]", slowest)
+	}
+}
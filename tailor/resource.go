@@ -1,20 +1,33 @@
 package tailor
 
 import (
+	"cmp"
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	tailorv1 "buf.build/gen/go/tailor-inc/tailor/protocolbuffers/go/tailor/v1"
 	"connectrpc.com/connect"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
 )
 
+var tracer = otel.Tracer("github.com/tailor-platform/patterner/tailor")
+
 type Resources struct {
 	Applications []*Application
 	Pipelines    []*Pipeline
 	TailorDBs    []*TailorDB
 	StateFlows   []*StateFlow
+	// FetchErrors are the errors of the resources that could not be fetched with WithKeepGoing.
+	FetchErrors []*FetchError
 
 	// Options
 	withoutApplications   bool
@@ -22,10 +35,36 @@ type Resources struct {
 	withoutPipeline       bool
 	withoutStateFlow      bool
 	executionResultsSince *time.Time
+	keepGoing             bool
+	refresh               bool
+	cache                 *resourceCache
+	executionResults      *executionResultStore
+	// filteredTypeNames are the names of the TailorDB types filtered out, which the pipelines not filtered out may still call.
+	filteredTypeNames []string
 
 	mu sync.Mutex
 }
 
+// FetchError is an error of fetching a namespace or a resource.
+type FetchError struct {
+	Type      LintTargetType
+	Namespace string
+	// Resource is the resolver or type name. It is empty when the namespace could not be fetched.
+	Resource string
+	Err      error
+}
+
+func (e *FetchError) Error() string {
+	if e.Resource == "" {
+		return fmt.Sprintf("failed to fetch %s namespace %s: %v", e.Type, e.Namespace, e.Err)
+	}
+	return fmt.Sprintf("failed to fetch %s %s/%s: %v", e.Type, e.Namespace, e.Resource, e.Err)
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
 type Application struct {
 	Name string
 }
@@ -168,7 +207,29 @@ func WithExecutionResults(since *time.Time) ResourceOption {
 	}
 }
 
-func (c *Client) Resources(ctx context.Context, opts ...ResourceOption) (*Resources, error) {
+// WithKeepGoing continues fetching when a namespace or a resource cannot be fetched, and collects the errors into Resources.FetchErrors.
+func WithKeepGoing() ResourceOption {
+	return func(r *Resources) error {
+		r.keepGoing = true
+		return nil
+	}
+}
+
+// WithRefresh ignores the cached resources and refetches all of them.
+func WithRefresh() ResourceOption {
+	return func(r *Resources) error {
+		r.refresh = true
+		return nil
+	}
+}
+
+func (c *Client) Resources(ctx context.Context, opts ...ResourceOption) (_ *Resources, err error) {
+	ctx, span := tracer.Start(ctx, "Resources", trace.WithAttributes(attribute.String("tailor.workspace_id", c.cfg.WorkspaceID)))
+	defer func() { endSpan(span, err) }()
+
+	if c.tokens == nil {
+		return nil, ErrNoCredential
+	}
 	resources := &Resources{}
 	for _, opt := range opts {
 		if err := opt(resources); err != nil {
@@ -176,6 +237,13 @@ func (c *Client) Resources(ctx context.Context, opts ...ResourceOption) (*Resour
 		}
 	}
 
+	if c.cacheDir != "" {
+		resources.cache = loadResourceCache(c.cacheDir, c.cfg.WorkspaceID, c.cacheTTL, resources.refresh)
+		if resources.executionResultsSince != nil {
+			resources.executionResults = loadExecutionResultStore(c.cacheDir, c.cfg.WorkspaceID, resources.refresh)
+		}
+	}
+
 	// Create errgroup for top-level parallel execution
 	g, ctx := errgroup.WithContext(ctx)
 
@@ -204,12 +272,39 @@ func (c *Client) Resources(ctx context.Context, opts ...ResourceOption) (*Resour
 	if err := g.Wait(); err != nil {
 		return nil, err
 	}
+	if err := resources.cache.save(); err != nil {
+		return nil, fmt.Errorf("failed to save the resource cache: %w", err)
+	}
+	if err := resources.executionResults.save(); err != nil {
+		return nil, fmt.Errorf("failed to save the execution results: %w", err)
+	}
+	slices.SortFunc(resources.FetchErrors, func(a, b *FetchError) int {
+		return cmp.Or(
+			strings.Compare(string(a.Type), string(b.Type)),
+			strings.Compare(a.Namespace, b.Namespace),
+			strings.Compare(a.Resource, b.Resource),
+		)
+	})
 
 	return resources, nil
 }
 
+// group returns an errgroup bounded by the API concurrency. The interceptor bounds the in-flight calls,
+// and the limit keeps the fan-out from starting a goroutine per namespace, resolver and type at once.
+// The groups are nested (e.g., the resolvers of each namespace), so at most concurrency goroutines run per group.
+func (c *Client) group(ctx context.Context) (*errgroup.Group, context.Context) {
+	g, ctx := errgroup.WithContext(ctx)
+	if c.cfg.API.Concurrency > 0 {
+		g.SetLimit(c.cfg.API.Concurrency)
+	}
+	return g, ctx
+}
+
 // fetchPipelineServices fetches pipeline services in parallel.
-func (c *Client) fetchPipelineServices(ctx context.Context, resources *Resources) error {
+func (c *Client) fetchPipelineServices(ctx context.Context, resources *Resources) (err error) {
+	ctx, span := tracer.Start(ctx, "pipeline.services")
+	defer func() { endSpan(span, err) }()
+
 	pageToken := ""
 	for {
 		res, err := c.client.ListPipelineServices(ctx, connect.NewRequest(&tailorv1.ListPipelineServicesRequest{
@@ -222,11 +317,14 @@ func (c *Client) fetchPipelineServices(ctx context.Context, resources *Resources
 		}
 
 		// Process pipelines in parallel
-		g, ctx := errgroup.WithContext(ctx)
+		g, ctx := c.group(ctx)
 		var pipelines []*Pipeline
 		var mu sync.Mutex
 
 		for _, p := range res.Msg.GetPipelineServices() {
+			if !c.filter.namespace(p.GetNamespace().GetName()) {
+				continue
+			}
 			g.Go(func() error {
 				pipeline := &Pipeline{
 					NamespaceName: p.GetNamespace().GetName(),
@@ -234,7 +332,11 @@ func (c *Client) fetchPipelineServices(ctx context.Context, resources *Resources
 				}
 
 				if err := c.fetchPipelineResolvers(ctx, pipeline, p, resources); err != nil {
-					return err
+					return resources.keepGoingOn(ctx, &FetchError{
+						Type:      LintTargetTypePipeline,
+						Namespace: pipeline.NamespaceName,
+						Err:       err,
+					})
 				}
 
 				mu.Lock()
@@ -262,7 +364,10 @@ func (c *Client) fetchPipelineServices(ctx context.Context, resources *Resources
 }
 
 // fetchPipelineResolvers fetches pipeline resolvers in parallel.
-func (c *Client) fetchPipelineResolvers(ctx context.Context, pipeline *Pipeline, p *tailorv1.PipelineService, resources *Resources) error {
+func (c *Client) fetchPipelineResolvers(ctx context.Context, pipeline *Pipeline, p *tailorv1.PipelineService, resources *Resources) (err error) {
+	ctx, span := tracer.Start(ctx, "pipeline.namespace", trace.WithAttributes(attribute.String("tailor.namespace", p.GetNamespace().GetName())))
+	defer func() { endSpan(span, err) }()
+
 	pageToken := ""
 	for {
 		res, err := c.client.ListPipelineResolvers(ctx, connect.NewRequest(&tailorv1.ListPipelineResolversRequest{
@@ -276,15 +381,23 @@ func (c *Client) fetchPipelineResolvers(ctx context.Context, pipeline *Pipeline,
 		}
 
 		// Process resolvers in parallel
-		g, ctx := errgroup.WithContext(ctx)
+		g, ctx := c.group(ctx)
 		var resolvers []*PipelineResolver
 		var mu sync.Mutex
 
 		for _, r := range res.Msg.GetPipelineResolvers() {
+			if !c.filter.resolver(p.GetNamespace().GetName(), r.GetName()) {
+				continue
+			}
 			g.Go(func() error {
 				resolver, err := c.fetchPipelineResolverDetails(ctx, p, r, resources)
 				if err != nil {
-					return err
+					return resources.keepGoingOn(ctx, &FetchError{
+						Type:      LintTargetTypePipeline,
+						Namespace: p.GetNamespace().GetName(),
+						Resource:  r.GetName(),
+						Err:       err,
+					})
 				}
 
 				mu.Lock()
@@ -309,17 +422,42 @@ func (c *Client) fetchPipelineResolvers(ctx context.Context, pipeline *Pipeline,
 }
 
 // fetchPipelineResolverDetails fetches pipeline resolver details.
-func (c *Client) fetchPipelineResolverDetails(ctx context.Context, p *tailorv1.PipelineService, r *tailorv1.PipelineResolver, resources *Resources) (*PipelineResolver, error) {
-	res, err := c.client.GetPipelineResolver(ctx, connect.NewRequest(&tailorv1.GetPipelineResolverRequest{
-		WorkspaceId:   c.cfg.WorkspaceID,
-		NamespaceName: p.GetNamespace().GetName(),
-		ResolverName:  r.GetName(),
-	}))
-	if err != nil {
-		return nil, err
+func (c *Client) fetchPipelineResolverDetails(ctx context.Context, p *tailorv1.PipelineService, r *tailorv1.PipelineResolver, resources *Resources) (_ *PipelineResolver, err error) {
+	ctx, span := tracer.Start(ctx, "pipeline.resolver", trace.WithAttributes(
+		attribute.String("tailor.namespace", p.GetNamespace().GetName()),
+		attribute.String("tailor.resolver", r.GetName()),
+	))
+	defer func() { endSpan(span, err) }()
+
+	key := fmt.Sprintf("pipeline/%s/%s", p.GetNamespace().GetName(), r.GetName())
+	var d string
+	if resources.cache != nil {
+		if d, err = digest(r); err != nil {
+			return nil, err
+		}
+	}
+	rr := &tailorv1.PipelineResolver{}
+	if e, ok := resources.cache.get(key, d); ok && proto.Unmarshal(e.Detail, rr) == nil {
+		span.SetAttributes(attribute.Bool("tailor.cache_hit", true))
+	} else {
+		res, err := c.client.GetPipelineResolver(ctx, connect.NewRequest(&tailorv1.GetPipelineResolverRequest{
+			WorkspaceId:   c.cfg.WorkspaceID,
+			NamespaceName: p.GetNamespace().GetName(),
+			ResolverName:  r.GetName(),
+		}))
+		if err != nil {
+			return nil, err
+		}
+		rr = res.Msg.GetPipelineResolver()
+		if resources.cache != nil {
+			b, err := proto.Marshal(rr)
+			if err != nil {
+				return nil, err
+			}
+			resources.cache.put(key, &cacheEntry{Digest: d, FetchedAt: time.Now(), Detail: b})
+		}
 	}
 
-	rr := res.Msg.GetPipelineResolver()
 	resolver := &PipelineResolver{
 		Name:          rr.GetName(),
 		Description:   rr.GetDescription(),
@@ -367,7 +505,16 @@ func (c *Client) fetchPipelineResolverDetails(ctx context.Context, p *tailorv1.P
 }
 
 // fetchExecutionResults fetches execution results for a resolver.
-func (c *Client) fetchExecutionResults(ctx context.Context, resolver *PipelineResolver, p *tailorv1.PipelineService, r *tailorv1.PipelineResolver, hasTest bool, resources *Resources) error {
+func (c *Client) fetchExecutionResults(ctx context.Context, resolver *PipelineResolver, p *tailorv1.PipelineService, r *tailorv1.PipelineResolver, hasTest bool, resources *Resources) (err error) {
+	ctx, span := tracer.Start(ctx, "pipeline.resolver.execution_results", trace.WithAttributes(
+		attribute.String("tailor.namespace", p.GetNamespace().GetName()),
+		attribute.String("tailor.resolver", r.GetName()),
+	))
+	defer func() {
+		span.SetAttributes(attribute.Int("tailor.execution_results", len(resolver.ExecutionResults)))
+		endSpan(span, err)
+	}()
+
 	pageToken := ""
 	view := tailorv1.PipelineResolverExecutionResultView_PIPELINE_RESOLVER_EXECUTION_RESULT_VIEW_BASIC
 	if hasTest {
@@ -375,6 +522,27 @@ func (c *Client) fetchExecutionResults(ctx context.Context, resolver *PipelineRe
 		view = tailorv1.PipelineResolverExecutionResultView_PIPELINE_RESOLVER_EXECUTION_RESULT_VIEW_FULL
 	}
 
+	since := *resources.executionResultsSince
+	key := fmt.Sprintf("%s/%s", p.GetNamespace().GetName(), r.GetName())
+	var stored []*tailorv1.PipelineResolverExecutionResult
+	fetchFrom := since
+	if cur, ok := resources.executionResults.get(key, view, since); ok {
+		stored = make([]*tailorv1.PipelineResolverExecutionResult, 0, len(cur.Results))
+		for _, b := range cur.Results {
+			er := &tailorv1.PipelineResolverExecutionResult{}
+			if err := proto.Unmarshal(b, er); err != nil {
+				stored = nil
+				break
+			}
+			stored = append(stored, er)
+		}
+		if stored != nil {
+			fetchFrom = cur.fetchFrom(since)
+		}
+	}
+	span.SetAttributes(attribute.Int("tailor.stored_execution_results", len(stored)))
+
+	var fetched []*tailorv1.PipelineResolverExecutionResult
 L:
 	for {
 		res, err := c.client.ListPipelineResolverExecutionResults(ctx, connect.NewRequest(&tailorv1.ListPipelineResolverExecutionResultsRequest{
@@ -389,23 +557,46 @@ L:
 			return err
 		}
 		for _, r := range res.Msg.GetResults() {
-			if r.GetCreatedAt().AsTime().Before(*resources.executionResultsSince) {
+			if r.GetCreatedAt().AsTime().Before(fetchFrom) {
 				// Since the results are ordered by CreatedAt descending,
 				// we can stop fetching more results once we reach an older entry.
 				break L
 			}
-			resolver.ExecutionResults = append(resolver.ExecutionResults, r)
+			fetched = append(fetched, r)
 		}
 		if res.Msg.GetNextPageToken() == "" {
 			break
 		}
 		pageToken = res.Msg.GetNextPageToken()
 	}
+	resolver.ExecutionResults = mergeExecutionResults(fetched, stored, since)
+
+	if resources.executionResults != nil {
+		cur := &executionResultCursor{
+			View:  view,
+			Since: since,
+		}
+		if len(resolver.ExecutionResults) > 0 {
+			cur.NewestCreatedAt = resolver.ExecutionResults[0].GetCreatedAt().AsTime()
+			cur.NewestID = resolver.ExecutionResults[0].GetId()
+		}
+		for _, er := range resolver.ExecutionResults {
+			b, err := proto.Marshal(storedExecutionResult(er))
+			if err != nil {
+				return err
+			}
+			cur.Results = append(cur.Results, b)
+		}
+		resources.executionResults.put(key, cur)
+	}
 	return nil
 }
 
 // fetchTailorDBServices fetches TailorDB services in parallel.
-func (c *Client) fetchTailorDBServices(ctx context.Context, resources *Resources) error {
+func (c *Client) fetchTailorDBServices(ctx context.Context, resources *Resources) (err error) {
+	ctx, span := tracer.Start(ctx, "tailordb.services")
+	defer func() { endSpan(span, err) }()
+
 	pageToken := ""
 	for {
 		res, err := c.client.ListTailorDBServices(ctx, connect.NewRequest(&tailorv1.ListTailorDBServicesRequest{
@@ -418,18 +609,35 @@ func (c *Client) fetchTailorDBServices(ctx context.Context, resources *Resources
 		}
 
 		// Process TailorDB services in parallel
-		g, ctx := errgroup.WithContext(ctx)
+		g, ctx := c.group(ctx)
 		var tailordbs []*TailorDB
 		var mu sync.Mutex
 
 		for _, t := range res.Msg.GetTailordbServices() {
+			if !c.filter.namespace(t.GetNamespace().GetName()) {
+				g.Go(func() error {
+					if err := c.listFilteredTailorDBTypeNames(ctx, t, resources); err != nil {
+						return resources.keepGoingOn(ctx, &FetchError{
+							Type:      LintTargetTypeTailorDB,
+							Namespace: t.GetNamespace().GetName(),
+							Err:       err,
+						})
+					}
+					return nil
+				})
+				continue
+			}
 			g.Go(func() error {
 				tailordb := &TailorDB{
 					NamespaceName: t.GetNamespace().GetName(),
 				}
 
-				if err := c.fetchTailorDBTypes(ctx, tailordb, t); err != nil {
-					return err
+				if err := c.fetchTailorDBTypes(ctx, tailordb, t, resources); err != nil {
+					return resources.keepGoingOn(ctx, &FetchError{
+						Type:      LintTargetTypeTailorDB,
+						Namespace: tailordb.NamespaceName,
+						Err:       err,
+					})
 				}
 
 				mu.Lock()
@@ -457,7 +665,10 @@ func (c *Client) fetchTailorDBServices(ctx context.Context, resources *Resources
 }
 
 // fetchTailorDBTypes fetches TailorDB types in parallel.
-func (c *Client) fetchTailorDBTypes(ctx context.Context, tailordb *TailorDB, t *tailorv1.TailorDBService) error {
+func (c *Client) fetchTailorDBTypes(ctx context.Context, tailordb *TailorDB, t *tailorv1.TailorDBService, resources *Resources) (err error) {
+	ctx, span := tracer.Start(ctx, "tailordb.namespace", trace.WithAttributes(attribute.String("tailor.namespace", t.GetNamespace().GetName())))
+	defer func() { endSpan(span, err) }()
+
 	pageToken := ""
 	for {
 		res, err := c.client.ListTailorDBTypes(ctx, connect.NewRequest(&tailorv1.ListTailorDBTypesRequest{
@@ -471,15 +682,25 @@ func (c *Client) fetchTailorDBTypes(ctx context.Context, tailordb *TailorDB, t *
 		}
 
 		// Process types in parallel
-		g, ctx := errgroup.WithContext(ctx)
+		g, ctx := c.group(ctx)
 		var types []*TailorDBType
 		var mu sync.Mutex
 
+		var filtered []string
 		for _, tt := range res.Msg.GetTailordbTypes() {
+			if !c.filter.tailorDBType(t.GetNamespace().GetName(), tt.GetName()) {
+				filtered = append(filtered, tt.GetName())
+				continue
+			}
 			g.Go(func() error {
-				tailordbType, err := c.fetchTailorDBTypeDetails(ctx, t, tt)
+				tailordbType, err := c.fetchTailorDBTypeDetails(ctx, t, tt, resources)
 				if err != nil {
-					return err
+					return resources.keepGoingOn(ctx, &FetchError{
+						Type:      LintTargetTypeTailorDB,
+						Namespace: t.GetNamespace().GetName(),
+						Resource:  tt.GetName(),
+						Err:       err,
+					})
 				}
 
 				mu.Lock()
@@ -494,6 +715,7 @@ func (c *Client) fetchTailorDBTypes(ctx context.Context, tailordb *TailorDB, t *
 		}
 
 		tailordb.Types = append(tailordb.Types, types...)
+		resources.addFilteredTypeNames(filtered)
 
 		if res.Msg.GetNextPageToken() == "" {
 			break
@@ -504,17 +726,51 @@ func (c *Client) fetchTailorDBTypes(ctx context.Context, tailordb *TailorDB, t *
 }
 
 // fetchTailorDBTypeDetails fetches TailorDB type details.
-func (c *Client) fetchTailorDBTypeDetails(ctx context.Context, t *tailorv1.TailorDBService, tt *tailorv1.TailorDBType) (*TailorDBType, error) {
-	res, err := c.client.GetTailorDBType(ctx, connect.NewRequest(&tailorv1.GetTailorDBTypeRequest{
-		WorkspaceId:      c.cfg.WorkspaceID,
-		NamespaceName:    t.GetNamespace().GetName(),
-		TailordbTypeName: tt.GetName(),
-	}))
-	if err != nil {
-		return nil, err
+func (c *Client) fetchTailorDBTypeDetails(ctx context.Context, t *tailorv1.TailorDBService, tt *tailorv1.TailorDBType, resources *Resources) (_ *TailorDBType, err error) {
+	ctx, span := tracer.Start(ctx, "tailordb.type", trace.WithAttributes(
+		attribute.String("tailor.namespace", t.GetNamespace().GetName()),
+		attribute.String("tailor.type", tt.GetName()),
+	))
+	defer func() { endSpan(span, err) }()
+
+	key := fmt.Sprintf("tailordb/%s/%s", t.GetNamespace().GetName(), tt.GetName())
+	var d string
+	if resources.cache != nil {
+		if d, err = digest(tt); err != nil {
+			return nil, err
+		}
+	}
+	ttt := &tailorv1.TailorDBType{}
+	var gqlPermission bool
+	if e, ok := resources.cache.get(key, d); ok && proto.Unmarshal(e.Detail, ttt) == nil {
+		span.SetAttributes(attribute.Bool("tailor.cache_hit", true))
+		gqlPermission = e.GQLPermission
+	} else {
+		res, err := c.client.GetTailorDBType(ctx, connect.NewRequest(&tailorv1.GetTailorDBTypeRequest{
+			WorkspaceId:      c.cfg.WorkspaceID,
+			NamespaceName:    t.GetNamespace().GetName(),
+			TailordbTypeName: tt.GetName(),
+		}))
+		if err != nil {
+			return nil, err
+		}
+		ttt = res.Msg.GetTailordbType()
+		if _, err := c.client.GetTailorDBGQLPermission(ctx, connect.NewRequest(&tailorv1.GetTailorDBGQLPermissionRequest{
+			WorkspaceId:   c.cfg.WorkspaceID,
+			NamespaceName: t.GetNamespace().GetName(),
+			TypeName:      tt.GetName(),
+		})); err == nil {
+			gqlPermission = true
+		}
+		if resources.cache != nil {
+			b, err := proto.Marshal(ttt)
+			if err != nil {
+				return nil, err
+			}
+			resources.cache.put(key, &cacheEntry{Digest: d, FetchedAt: time.Now(), Detail: b, GQLPermission: gqlPermission})
+		}
 	}
 
-	ttt := res.Msg.GetTailordbType()
 	tailordbType := &TailorDBType{
 		Name:        ttt.GetName(),
 		Description: ttt.GetSchema().GetDescription(),
@@ -531,11 +787,7 @@ func (c *Client) fetchTailorDBTypeDetails(ctx context.Context, t *tailorv1.Tailo
 	if ttt.GetSchema().GetRecordPermission() != nil {
 		tailordbType.RecordPermission = &TailorDBRecordPermission{}
 	}
-	if _, err := c.client.GetTailorDBGQLPermission(ctx, connect.NewRequest(&tailorv1.GetTailorDBGQLPermissionRequest{
-		WorkspaceId:   c.cfg.WorkspaceID,
-		NamespaceName: t.GetNamespace().GetName(),
-		TypeName:      tt.GetName(),
-	})); err == nil {
+	if gqlPermission {
 		tailordbType.GQLPermission = &TailorDBGQLPermission{}
 	}
 
@@ -543,7 +795,10 @@ func (c *Client) fetchTailorDBTypeDetails(ctx context.Context, t *tailorv1.Tailo
 }
 
 // fetchStateFlowServices fetches StateFlow services.
-func (c *Client) fetchStateFlowServices(ctx context.Context, resources *Resources) error {
+func (c *Client) fetchStateFlowServices(ctx context.Context, resources *Resources) (err error) {
+	ctx, span := tracer.Start(ctx, "stateflow.services")
+	defer func() { endSpan(span, err) }()
+
 	pageToken := ""
 	for {
 		res, err := c.client.ListStateflowServices(ctx, connect.NewRequest(&tailorv1.ListStateflowServicesRequest{
@@ -557,6 +812,9 @@ func (c *Client) fetchStateFlowServices(ctx context.Context, resources *Resource
 
 		var stateflows []*StateFlow
 		for _, s := range res.Msg.GetStateflowServices() {
+			if !c.filter.namespace(s.GetNamespace().GetName()) {
+				continue
+			}
 			stateflow := &StateFlow{
 				NamespaceName: s.GetNamespace().GetName(),
 			}
@@ -583,6 +841,74 @@ func (c *Client) fetchStateFlowServices(ctx context.Context, resources *Resource
 	return nil
 }
 
+// listFilteredTailorDBTypeNames lists the names of the TailorDB types of a namespace filtered out, without fetching their details.
+func (c *Client) listFilteredTailorDBTypeNames(ctx context.Context, t *tailorv1.TailorDBService, resources *Resources) (err error) {
+	ctx, span := tracer.Start(ctx, "tailordb.filteredNamespace", trace.WithAttributes(attribute.String("tailor.namespace", t.GetNamespace().GetName())))
+	defer func() { endSpan(span, err) }()
+
+	pageToken := ""
+	for {
+		res, err := c.client.ListTailorDBTypes(ctx, connect.NewRequest(&tailorv1.ListTailorDBTypesRequest{
+			WorkspaceId:   c.cfg.WorkspaceID,
+			NamespaceName: t.GetNamespace().GetName(),
+			PageSize:      pageSize,
+			PageToken:     pageToken,
+		}))
+		if err != nil {
+			return err
+		}
+		var names []string
+		for _, tt := range res.Msg.GetTailordbTypes() {
+			names = append(names, tt.GetName())
+		}
+		resources.addFilteredTypeNames(names)
+		if res.Msg.GetNextPageToken() == "" {
+			break
+		}
+		pageToken = res.Msg.GetNextPageToken()
+	}
+	return nil
+}
+
+func (r *Resources) addFilteredTypeNames(names []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.filteredTypeNames = append(r.filteredTypeNames, names...)
+}
+
+// tailorDBTypeNames returns the names of all the TailorDB types of the workspace, including the ones filtered out,
+// so that filtering the types or the namespaces does not hide the calls to them from the pipelines not filtered out.
+func (r *Resources) tailorDBTypeNames() []string {
+	names := slices.Clone(r.filteredTypeNames)
+	for _, db := range r.TailorDBs {
+		for _, t := range db.Types {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+// keepGoingOn records the fetch error and returns nil to skip the failed namespace or resource in keep-going mode.
+// Otherwise, or when the context is canceled, it returns the error.
+func (r *Resources) keepGoingOn(ctx context.Context, fetchErr *FetchError) error {
+	if !r.keepGoing || ctx.Err() != nil {
+		return fetchErr.Err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.FetchErrors = append(r.FetchErrors, fetchErr)
+	return nil
+}
+
+// endSpan records the error on the span, if any, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
 // convertTailorDBFields converts proto FieldConfig map to TailorDBField slice.
 func convertTailorDBFields(fields map[string]*tailorv1.TailorDBType_FieldConfig) []*TailorDBField {
 	if fields == nil {
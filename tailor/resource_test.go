@@ -0,0 +1,80 @@
+package tailor
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestResources_keepGoingOn(t *testing.T) {
+	fetchErr := &FetchError{
+		Type:      LintTargetTypeTailorDB,
+		Namespace: "test-db",
+		Resource:  "User",
+		Err:       errors.New("internal error"),
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name          string
+		keepGoing     bool
+		ctx           context.Context
+		wantErr       bool
+		wantCollected int
+	}{
+		{
+			name:    "fail fast",
+			ctx:     context.Background(),
+			wantErr: true,
+		},
+		{
+			name:          "keep going",
+			keepGoing:     true,
+			ctx:           context.Background(),
+			wantCollected: 1,
+		},
+		{
+			name:      "keep going with canceled context",
+			keepGoing: true,
+			ctx:       canceled,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Resources{keepGoing: tt.keepGoing}
+			err := r.keepGoingOn(tt.ctx, fetchErr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("keepGoingOn() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, fetchErr.Err) {
+				t.Errorf("keepGoingOn() error = %v, want %v", err, fetchErr.Err)
+			}
+			if len(r.FetchErrors) != tt.wantCollected {
+				t.Errorf("got %d fetch errors, want %d", len(r.FetchErrors), tt.wantCollected)
+			}
+		})
+	}
+}
+
+func TestFetchError_Error(t *testing.T) {
+	tests := []struct {
+		err  *FetchError
+		want string
+	}{
+		{
+			err:  &FetchError{Type: LintTargetTypePipeline, Namespace: "ns", Err: errors.New("unavailable")},
+			want: "failed to fetch pipeline namespace ns: unavailable",
+		},
+		{
+			err:  &FetchError{Type: LintTargetTypeTailorDB, Namespace: "db", Resource: "User", Err: errors.New("not found")},
+			want: "failed to fetch tailordb db/User: not found",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
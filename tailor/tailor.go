@@ -4,9 +4,15 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"buf.build/gen/go/tailor-inc/tailor/connectrpc/go/tailor/v1/tailorv1connect"
+	"connectrpc.com/connect"
+	"github.com/k1LoW/duration"
 	"github.com/tailor-platform/patterner/config"
 	"github.com/tailor-platform/patterner/version"
 )
@@ -14,6 +20,12 @@ import (
 type Client struct {
 	client tailorv1connect.OperatorServiceClient
 	cfg    *config.Config
+	// cacheDir is the directory of the resource cache. The cache is disabled when it is empty.
+	cacheDir string
+	cacheTTL time.Duration
+	filter   *resourceFilter
+	// tokens is nil when no credential is found.
+	tokens *tokenSource
 }
 
 func New(cfg *config.Config) (*Client, error) {
@@ -24,38 +36,120 @@ func New(cfg *config.Config) (*Client, error) {
 		return nil, errors.New("workspace ID is required")
 	}
 
-	baseURL := "https://api.tailor.tech"
-	if platformURL := os.Getenv("PLATFORM_URL"); platformURL != "" {
-		baseURL = platformURL
-	}
+	baseURL := platformBaseURL()
 
 	// Create HTTP client with Bearer token authorization and User-Agent
-	httpClient := &http.Client{}
-	if token := os.Getenv("TAILOR_TOKEN"); token != "" {
-		httpClient = &http.Client{
-			Transport: &bearerTokenTransport{
-				token:     token,
-				userAgent: fmt.Sprintf("%s/%s", version.Name, version.Version),
-				base:      http.DefaultTransport,
-			},
+	tokens, err := newTokenSource(cfg.Auth, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{
+		Transport: &bearerTokenTransport{
+			tokens:    tokens,
+			userAgent: fmt.Sprintf("%s/%s", version.Name, version.Version),
+			base:      http.DefaultTransport,
+		},
+	}
+
+	var timeout time.Duration
+	if cfg.API.Timeout != "" {
+		d, err := duration.Parse(cfg.API.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid API timeout %q: %w", cfg.API.Timeout, err)
 		}
+		timeout = d
+	}
+	interceptor := newAPIInterceptor(cfg.API.Concurrency, timeout, cfg.API.MaxRetries)
+	filter, err := newResourceFilter(cfg.Filter)
+	if err != nil {
+		return nil, err
 	}
 
-	return &Client{
-		client: tailorv1connect.NewOperatorServiceClient(httpClient, baseURL),
+	c := &Client{
+		client: tailorv1connect.NewOperatorServiceClient(httpClient, baseURL, connect.WithInterceptors(interceptor)),
 		cfg:    cfg,
-	}, nil
+		filter: filter,
+		tokens: tokens,
+	}
+	if cfg.Cache.Enabled {
+		dir := cfg.Cache.Dir
+		if dir == "" {
+			userDir, err := os.UserCacheDir()
+			if err != nil {
+				// e.g., neither HOME nor XDG_CACHE_HOME is set in a CI container.
+				userDir = os.TempDir()
+				fmt.Fprintf(os.Stderr, "warning: failed to determine the user cache directory, caching the resources in %s: %v\n", userDir, err)
+			}
+			dir = filepath.Join(userDir, version.Name)
+		}
+		// The workspace IDs are unique only within a platform, so the caches are separated by the API host.
+		c.cacheDir = filepath.Join(dir, cacheHostDir(baseURL))
+		if cfg.Cache.TTL != "" {
+			d, err := duration.Parse(cfg.Cache.TTL)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cache TTL %q: %w", cfg.Cache.TTL, err)
+			}
+			c.cacheTTL = d
+		}
+	}
+
+	return c, nil
+}
+
+// platformBaseURL returns the base URL of the API of the platform, which PLATFORM_URL overrides.
+func platformBaseURL() string {
+	if platformURL := os.Getenv("PLATFORM_URL"); platformURL != "" {
+		return platformURL
+	}
+	return "https://api.tailor.tech"
+}
+
+// cacheHostDir returns the name of the cache directory for the API of the base URL.
+func cacheHostDir(baseURL string) string {
+	host := baseURL
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	return strings.NewReplacer(":", "_", "/", "_", "\\", "_").Replace(host)
 }
 
 // bearerTokenTransport implements http.RoundTripper to add Bearer token and User-Agent to requests.
+// When the API rejects the token, it refreshes the token and retries the request once.
 type bearerTokenTransport struct {
-	token     string
+	// tokens is nil when no credential is found, in which case the requests are sent without a token.
+	tokens    *tokenSource
 	userAgent string
 	base      http.RoundTripper
 }
 
 func (t *bearerTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	req.Header.Set("Authorization", "Bearer "+t.token)
+	req = req.Clone(req.Context())
 	req.Header.Set("User-Agent", t.userAgent)
-	return t.base.RoundTrip(req)
+	if t.tokens == nil {
+		return t.base.RoundTrip(req)
+	}
+	token, err := t.tokens.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := t.base.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized || (req.Body != nil && req.GetBody == nil) {
+		return res, err
+	}
+	_ = res.Body.Close()
+	token, err = t.tokens.Refresh(req.Context(), token)
+	if err != nil {
+		return nil, err
+	}
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	retry.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(retry)
 }
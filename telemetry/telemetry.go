@@ -0,0 +1,144 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/tailor-platform/patterner/tailor"
+	"github.com/tailor-platform/patterner/version"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	scopeName    = "github.com/tailor-platform/patterner"
+	metricPrefix = "patterner."
+)
+
+// Endpoint is an OTLP/HTTP endpoint such as http://localhost:4318.
+type Endpoint struct {
+	host     string
+	basePath string
+	insecure bool
+}
+
+// ParseEndpoint parses the base URL of an OTLP/HTTP endpoint.
+// The signal paths (/v1/traces, /v1/metrics) are appended to the path of the URL.
+func ParseEndpoint(rawURL string) (*Endpoint, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q: %w", rawURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q: scheme must be http or https", rawURL)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q: host is required", rawURL)
+	}
+	return &Endpoint{
+		host:     u.Host,
+		basePath: u.Path,
+		insecure: u.Scheme == "http",
+	}, nil
+}
+
+// StartTracing sets the global tracer provider to export spans to the endpoint.
+// The returned function flushes the pending spans and stops tracing.
+func StartTracing(ctx context.Context, e *Endpoint) (func(context.Context) error, error) {
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(e.host),
+		otlptracehttp.WithURLPath(path.Join("/", e.basePath, "v1/traces")),
+	}
+	if e.insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(newResource()),
+	)
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	return func(ctx context.Context) error {
+		otel.SetTracerProvider(prev)
+		return tp.Shutdown(ctx)
+	}, nil
+}
+
+// PushMetrics exports the metrics of the workspace to the endpoint as gauges.
+func PushMetrics(ctx context.Context, e *Endpoint, workspaceID string, metrics []tailor.Metric) error {
+	opts := []otlpmetrichttp.Option{
+		otlpmetrichttp.WithEndpoint(e.host),
+		otlpmetrichttp.WithURLPath(path.Join("/", e.basePath, "v1/metrics")),
+	}
+	if e.insecure {
+		opts = append(opts, otlpmetrichttp.WithInsecure())
+	}
+	exporter, err := otlpmetrichttp.New(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	if err := exporter.Export(ctx, resourceMetrics(workspaceID, metrics, time.Now())); err != nil {
+		return errors.Join(err, exporter.Shutdown(ctx))
+	}
+	return exporter.Shutdown(ctx)
+}
+
+// resourceMetrics converts the metrics into OTLP gauges.
+// Metrics with errors are skipped.
+func resourceMetrics(workspaceID string, metrics []tailor.Metric, now time.Time) *metricdata.ResourceMetrics {
+	attrs := attribute.NewSet(attribute.String("tailor.workspace_id", workspaceID))
+	sm := metricdata.ScopeMetrics{
+		Scope: instrumentation.Scope{
+			Name:    scopeName,
+			Version: version.Version,
+		},
+	}
+	for _, m := range metrics {
+		if m.Error != nil {
+			continue
+		}
+		unit := m.Unit
+		if unit == "" {
+			unit = "1"
+		}
+		sm.Metrics = append(sm.Metrics, metricdata.Metrics{
+			Name:        metricPrefix + m.Key,
+			Description: m.Name,
+			Unit:        unit,
+			Data: metricdata.Gauge[float64]{
+				DataPoints: []metricdata.DataPoint[float64]{
+					{
+						Attributes: attrs,
+						Time:       now,
+						Value:      m.Value,
+					},
+				},
+			},
+		})
+	}
+	return &metricdata.ResourceMetrics{
+		Resource:     newResource(),
+		ScopeMetrics: []metricdata.ScopeMetrics{sm},
+	}
+}
+
+func newResource() *resource.Resource {
+	return resource.NewSchemaless(
+		attribute.String("service.name", version.Name),
+		attribute.String("service.version", version.Version),
+	)
+}
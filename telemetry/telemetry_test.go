@@ -0,0 +1,284 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tailor-platform/patterner/config"
+	"github.com/tailor-platform/patterner/tailor"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// receiver is an OTLP/HTTP receiver recording the exported metrics and spans.
+type receiver struct {
+	mu      sync.Mutex
+	metrics []*collectormetrics.ExportMetricsServiceRequest
+	traces  []*collectortrace.ExportTraceServiceRequest
+}
+
+// requests returns the recorded export requests.
+func (rcv *receiver) requests() ([]*collectormetrics.ExportMetricsServiceRequest, []*collectortrace.ExportTraceServiceRequest) {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	return rcv.metrics, rcv.traces
+}
+
+func newReceiver(t *testing.T) (*receiver, *Endpoint) {
+	t.Helper()
+	rcv := &receiver{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /otlp/v1/metrics", func(w http.ResponseWriter, r *http.Request) {
+		req := &collectormetrics.ExportMetricsServiceRequest{}
+		if !decode(t, r, req) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		rcv.mu.Lock()
+		rcv.metrics = append(rcv.metrics, req)
+		rcv.mu.Unlock()
+		writeResponse(t, w, &collectormetrics.ExportMetricsServiceResponse{})
+	})
+	mux.HandleFunc("POST /otlp/v1/traces", func(w http.ResponseWriter, r *http.Request) {
+		req := &collectortrace.ExportTraceServiceRequest{}
+		if !decode(t, r, req) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		rcv.mu.Lock()
+		rcv.traces = append(rcv.traces, req)
+		rcv.mu.Unlock()
+		writeResponse(t, w, &collectortrace.ExportTraceServiceResponse{})
+	})
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	e, err := ParseEndpoint(s.URL + "/otlp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rcv, e
+}
+
+func decode(t *testing.T, r *http.Request, m proto.Message) bool {
+	t.Helper()
+	if got := r.Header.Get("Content-Type"); got != "application/x-protobuf" {
+		t.Errorf("got content type %q, want application/x-protobuf", got)
+		return false
+	}
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Error(err)
+		return false
+	}
+	if err := proto.Unmarshal(b, m); err != nil {
+		t.Error(err)
+		return false
+	}
+	return true
+}
+
+func writeResponse(t *testing.T, w http.ResponseWriter, m proto.Message) {
+	t.Helper()
+	b, err := proto.Marshal(m)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(b)
+}
+
+func TestPushMetrics(t *testing.T) {
+	rcv, e := newReceiver(t)
+	metrics := []tailor.Metric{
+		{Key: "pipeline_resolvers_total", Name: "Pipeline resolvers", Value: 3},
+		{Key: "broken", Name: "Broken", Error: errors.New("failed")},
+	}
+	if err := PushMetrics(context.Background(), e, "test-workspace-id", metrics); err != nil {
+		t.Fatal(err)
+	}
+	exports, _ := rcv.requests()
+	if len(exports) != 1 {
+		t.Fatalf("got %d metric exports, want 1", len(exports))
+	}
+	rms := exports[0].GetResourceMetrics()
+	if len(rms) != 1 || len(rms[0].GetScopeMetrics()) != 1 {
+		t.Fatalf("got %d resource metrics, want 1 with 1 scope", len(rms))
+	}
+	got := rms[0].GetScopeMetrics()[0].GetMetrics()
+	if len(got) != 1 {
+		t.Fatalf("got %d metrics, want 1", len(got))
+	}
+	if got[0].GetName() != "patterner.pipeline_resolvers_total" {
+		t.Errorf("got name %q, want patterner.pipeline_resolvers_total", got[0].GetName())
+	}
+	dps := got[0].GetGauge().GetDataPoints()
+	if len(dps) != 1 || dps[0].GetAsDouble() != 3 {
+		t.Fatalf("got data points %v, want a gauge of 3", dps)
+	}
+	attrs := dps[0].GetAttributes()
+	if len(attrs) != 1 || attrs[0].GetKey() != "tailor.workspace_id" || attrs[0].GetValue().GetStringValue() != "test-workspace-id" {
+		t.Errorf("got attributes %v, want the workspace ID", attrs)
+	}
+}
+
+func TestStartTracing(t *testing.T) {
+	rcv, e := newReceiver(t)
+	t.Setenv("TAILOR_TOKEN", "test-token")
+	cfg, err := config.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.WorkspaceID = "test-workspace-id"
+	cfg.Cache.Enabled = false
+	c, err := tailor.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prev := otel.GetTracerProvider()
+	ctx := context.Background()
+	stop, err := StartTracing(ctx, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otel.GetTracerProvider() == prev {
+		t.Error("the global tracer provider is not set")
+	}
+	// Fetching no resources calls no API but still traces the fetch.
+	if _, err := c.Resources(ctx, tailor.WithoutApplications(), tailor.WithoutPipeline(), tailor.WithoutTailorDB(), tailor.WithoutStateFlow()); err != nil {
+		t.Fatal(err)
+	}
+	if err := stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if otel.GetTracerProvider() != prev {
+		t.Error("the previous global tracer provider is not restored")
+	}
+
+	_, exports := rcv.requests()
+	var found bool
+	for _, req := range exports {
+		for _, rs := range req.GetResourceSpans() {
+			for _, ss := range rs.GetScopeSpans() {
+				for _, span := range ss.GetSpans() {
+					if span.GetName() != "Resources" {
+						continue
+					}
+					found = true
+					attrs := span.GetAttributes()
+					if len(attrs) != 1 || attrs[0].GetKey() != "tailor.workspace_id" || attrs[0].GetValue().GetStringValue() != "test-workspace-id" {
+						t.Errorf("got attributes %v, want the workspace ID", attrs)
+					}
+				}
+			}
+		}
+	}
+	if !found {
+		t.Errorf("the fetch span is not exported: %v", exports)
+	}
+}
+
+func TestParseEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		rawURL  string
+		want    *Endpoint
+		wantErr bool
+	}{
+		{
+			name:   "http",
+			rawURL: "http://localhost:4318",
+			want:   &Endpoint{host: "localhost:4318", insecure: true},
+		},
+		{
+			name:   "https with path",
+			rawURL: "https://otel.example.com/otlp",
+			want:   &Endpoint{host: "otel.example.com", basePath: "/otlp"},
+		},
+		{
+			name:    "grpc scheme",
+			rawURL:  "grpc://localhost:4317",
+			wantErr: true,
+		},
+		{
+			name:    "no host",
+			rawURL:  "http:///v1",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEndpoint(tt.rawURL)
+			if err != nil {
+				if !tt.wantErr {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if tt.wantErr {
+				t.Fatal("expected error")
+			}
+			if *got != *tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceMetrics(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	metrics := []tailor.Metric{
+		{Key: "pipeline_resolvers_total", Name: "Pipeline resolvers", Value: 3},
+		{Key: "pipeline_resolver_step_coverage_percentage", Name: "Pipeline resolver step coverage", Value: 50, Unit: "%"},
+		{Key: "broken", Name: "Broken", Error: errors.New("failed")},
+	}
+	rm := resourceMetrics("test-workspace-id", metrics, now)
+	if len(rm.ScopeMetrics) != 1 {
+		t.Fatalf("got %d scope metrics, want 1", len(rm.ScopeMetrics))
+	}
+	got := rm.ScopeMetrics[0].Metrics
+	if len(got) != 2 {
+		t.Fatalf("got %d metrics, want 2", len(got))
+	}
+	want := []struct {
+		name  string
+		unit  string
+		value float64
+	}{
+		{name: "patterner.pipeline_resolvers_total", unit: "1", value: 3},
+		{name: "patterner.pipeline_resolver_step_coverage_percentage", unit: "%", value: 50},
+	}
+	for i, w := range want {
+		if got[i].Name != w.name {
+			t.Errorf("got name %q, want %q", got[i].Name, w.name)
+		}
+		if got[i].Unit != w.unit {
+			t.Errorf("got unit %q, want %q", got[i].Unit, w.unit)
+		}
+		gauge, ok := got[i].Data.(metricdata.Gauge[float64])
+		if !ok {
+			t.Fatalf("got %T, want gauge", got[i].Data)
+		}
+		dp := gauge.DataPoints[0]
+		if dp.Value != w.value {
+			t.Errorf("got value %v, want %v", dp.Value, w.value)
+		}
+		if !dp.Time.Equal(now) {
+			t.Errorf("got time %v, want %v", dp.Time, now)
+		}
+		v, ok := dp.Attributes.Value("tailor.workspace_id")
+		if !ok || v.AsString() != "test-workspace-id" {
+			t.Errorf("got workspace id %v, want test-workspace-id", v.AsString())
+		}
+	}
+}